@@ -11,12 +11,12 @@
 package conf
 
 import (
+	"context"
+	"io/fs"
 	"log"
+	"os"
+	"reflect"
 	"sync"
-
-	// "time"
-
-	"github.com/fsnotify/fsnotify"
 )
 
 var (
@@ -24,53 +24,107 @@ var (
 	confLock = new(sync.RWMutex)
 )
 
+// Init toml file config
+//
+// Passing the file content as filePath with embed1 true is kept for
+// compatibility, use InitFS with an embed.FS. Init only decodes the
+// file, Load is the entry point of the defaults, validation and the
+// other options, e.g. Load(path, config, WithDefaults(), WithValidate()).
+func Init(filePath string, config interface{}, embed1 ...bool) (err error) {
+	var fileBytes []byte
+	name := filePath
+	if len(embed1) > 0 {
+		fileBytes, name = []byte(filePath), ""
+	} else {
+		fileBytes, err = os.ReadFile(filePath)
+	}
+	if err != nil {
+		log.Println("Toml init os.ReadFile error: ", err)
+		return err
+	}
+	if name != "" && hasInclude(fileBytes, TOML) {
+		return Load(filePath, config)
+	}
+
+	confLock.Lock()
+	defer confLock.Unlock()
+	return decodeFile(name, fileBytes, config, false)
+}
+
+// InitFS init the toml config file name in the file system,
+// e.g. an embed.FS, os.DirFS or fstest.MapFS
+func InitFS(fsys fs.FS, name string, config interface{}) error {
+	fileBytes, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		log.Println("Toml init fs.ReadFile error: ", err)
+		return err
+	}
+	if hasInclude(fileBytes, TOML) {
+		return LoadFS(fsys, name, config)
+	}
+
+	confLock.Lock()
+	defer confLock.Unlock()
+	return decodeFile(name, fileBytes, config, false)
+}
+
 // GoWatch go watch the paths
+//
+// Deprecated: the config is decoded while other goroutines read it,
+// use WatchFile.
 func GoWatch(paths string, cf interface{}) {
 	Init(paths, cf)
 	go Watch(paths, cf)
 }
 
 // NewWatcher new fsnotify watcher
+//
+// Deprecated: use WatchFile.
 func NewWatcher(paths string, config interface{}) {
 	Watch(paths, config)
 }
 
 // Watch new fsnotify watcher
+//
+// The directory of the file and of its included files are watched, so
+// atomic saves, renames and symlink swaps are followed, and a reload
+// that fails to parse keeps the last good config.
+//
+// Deprecated: Watch blocks forever and exits on errors, use WatchFile.
 func Watch(paths string, config interface{}) {
-	watcher, err := fsnotify.NewWatcher()
+	fw, err := newFileWatch(DefaultDebounce, configFiles(paths, TOML)...)
 	if err != nil {
 		log.Fatal("Conf Watch fsnotify.NewWatcher() error: ", err)
 	}
-	defer watcher.Close()
-
-	done := make(chan bool)
-	go func() {
-		for {
-			select {
-			case event := <-watcher.Events:
-				log.Println("Conf fsnotify watcher events: ", event)
 
-				// if event.Op&fsnotify.Chmod == fsnotify.Chmod {
-				// 	log.Println("fsnotify watcher.Events: ignore CHMOD event: ", event)
-				// 	continue
-				// }
-
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					// log.Println("modified file: ", event.Name)
-					err := Init(paths, config)
-					if err == nil {
-						log.Println("Conf fsnotify.Write config: ", config)
-					}
-				}
-			case err := <-watcher.Errors:
-				log.Println("Conf fsnotify watcher.Errors error: ", err)
-			}
+	fw.run(context.Background(), func() []string {
+		err := reload(paths, config)
+		if err != nil {
+			log.Println("Conf reload error: ", err)
+		} else {
+			log.Println("Conf fsnotify.Write config: ", config)
 		}
-	}()
+		return configFiles(paths, TOML)
+	}, func(err error) {
+		log.Println("Conf fsnotify watcher.Errors error: ", err)
+	})
+}
 
-	err = watcher.Add(paths)
-	if err != nil {
-		log.Fatal("Conf fsnotify watcher.Add: ", err)
+// reload decode the toml file into a new value and copy it into config,
+// so config is not half-updated by a failed parse
+func reload(path string, config interface{}) error {
+	rv := reflect.ValueOf(config)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return Init(path, config)
 	}
-	<-done
+
+	fresh := reflect.New(rv.Elem().Type())
+	if err := Init(path, fresh.Interface()); err != nil {
+		return err
+	}
+
+	confLock.Lock()
+	rv.Elem().Set(fresh.Elem())
+	confLock.Unlock()
+	return nil
 }
@@ -1,8 +1,9 @@
 package conf
 
 import (
-	_ "embed"
+	"embed"
 	"log"
+	"os"
 	"testing"
 
 	"github.com/vcaesar/tt"
@@ -11,6 +12,9 @@ import (
 var (
 	//go:embed conf.toml
 	conf1 string
+
+	//go:embed conf.toml
+	confFS embed.FS
 )
 
 type Toml struct {
@@ -34,3 +38,18 @@ func TestEmbed(t *testing.T) {
 	tt.Nil(t, err)
 	tt.Equal(t, "conf", toml.Test)
 }
+
+func TestInitFS(t *testing.T) {
+	toml := Toml{}
+	err := InitFS(confFS, "conf.toml", &toml)
+	tt.Nil(t, err)
+	tt.Equal(t, "conf", toml.Test)
+
+	toml = Toml{}
+	err = InitFS(os.DirFS("../testdata"), "conf.toml", &toml)
+	tt.Nil(t, err)
+	tt.Equal(t, "conf", toml.Test)
+
+	err = InitFS(confFS, "none.toml", &toml)
+	tt.NotNil(t, err)
+}
@@ -0,0 +1,157 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/go-vgo/gt/pwd"
+)
+
+// EncPrefix the prefix of the encrypted config values
+const EncPrefix = "enc:v1:"
+
+// KeyEnv the default environment variable of the config key
+const KeyEnv = "CONF_KEY"
+
+// ErrKey the config key is not a key of GenKey
+var ErrKey = errors.New("conf: the key is not 32 base64 encoded bytes, see GenKey")
+
+// GenKey returns a new random config key, 32 base64 encoded bytes
+func GenKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// aesKey returns the AES-256 key of the config key, the passphrases
+// are rejected
+func aesKey(key []byte) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(key)))
+	if err != nil || len(raw) != 32 {
+		return nil, ErrKey
+	}
+	return raw, nil
+}
+
+// KeyFromEnv returns the config key in the environment variable,
+// $CONF_KEY by default
+func KeyFromEnv(name ...string) ([]byte, error) {
+	env := KeyEnv
+	if len(name) > 0 {
+		env = name[0]
+	}
+
+	key := os.Getenv(env)
+	if key == "" {
+		return nil, fmt.Errorf("conf: key $%s is not set", env)
+	}
+	if _, err := aesKey([]byte(key)); err != nil {
+		return nil, fmt.Errorf("conf: key $%s: %w", env, err)
+	}
+	return []byte(key), nil
+}
+
+// KeyFromFile returns the config key in the file
+func KeyFromFile(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key = bytes.TrimSpace(key)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("conf: key file %s is empty", path)
+	}
+	if _, err := aesKey(key); err != nil {
+		return nil, fmt.Errorf("conf: key file %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// Encrypt encrypt the plaintext of the config key path, e.g. db.pass,
+// into an enc:v1: value by AES-GCM with the config key of GenKey. The
+// path is authenticated, so the value does not decrypt at another path.
+func Encrypt(path, plaintext string, key []byte) (string, error) {
+	k, err := aesKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := pwd.AesEncrypt(k, []byte(plaintext), []byte(path))
+	if err != nil {
+		return "", err
+	}
+	return EncPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecryptValue decrypt the enc:v1: value of the config key path,
+// other values are returned as is
+func DecryptValue(path, value string, key []byte) (string, error) {
+	if !strings.HasPrefix(value, EncPrefix) {
+		return value, nil
+	}
+
+	k, err := aesKey(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(value[len(EncPrefix):])
+	if err != nil {
+		return "", err
+	}
+	plain, err := pwd.AesDecrypt(k, data, []byte(path))
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// Decrypt decrypt the enc:v1: string values of config in place, each
+// one with its key path, the errors of all the values are joined together
+func Decrypt(config interface{}, key []byte) error {
+	rv := reflect.ValueOf(config)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("conf: decrypt non-pointer %T", config)
+	}
+	if _, err := aesKey(key); err != nil {
+		return err
+	}
+
+	sv := collect(rv.Elem())
+	var errs []error
+	for _, s := range sv.order {
+		if !strings.HasPrefix(s.val, EncPrefix) {
+			continue
+		}
+
+		plain, err := DecryptValue(s.key, s.val, key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("conf: %s: decrypt: %v", s.key, err))
+			continue
+		}
+		s.set(plain)
+	}
+
+	for _, flush := range sv.flush {
+		flush()
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,83 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+func TestEncrypt(t *testing.T) {
+	key, err := GenKey()
+	tt.Nil(t, err)
+	t.Setenv(KeyEnv, key)
+
+	k, err := KeyFromEnv()
+	tt.Nil(t, err)
+	enc, err := Encrypt("pass", "pass", k)
+	tt.Nil(t, err)
+	tt.Equal(t, EncPrefix, enc[:len(EncPrefix)])
+	tags, err := Encrypt("tags.db", "pass", k)
+	tt.Nil(t, err)
+
+	s := Secrets{Pass: enc, Host: "localhost", Tags: map[string]string{"db": tags}}
+	tt.Nil(t, Decrypt(&s, k))
+	tt.Equal(t, "pass", s.Pass)
+	tt.Equal(t, "pass", s.Tags["db"])
+	tt.Equal(t, "localhost", s.Host)
+
+	s.Pass = enc[:len(enc)-4] + "AAA="
+	err = Decrypt(&s, k)
+	tt.NotNil(t, err)
+	tt.Equal(t, "conf: pass: decrypt: cipher: message authentication failed", err.Error())
+
+	// a value copied to another key does not decrypt
+	s = Secrets{Key: enc}
+	err = Decrypt(&s, k)
+	tt.Equal(t, "conf: key: decrypt: cipher: message authentication failed", err.Error())
+
+	_, err = KeyFromEnv("NO_SUCH_KEY")
+	tt.NotNil(t, err)
+}
+
+func TestEncryptKey(t *testing.T) {
+	t.Setenv(KeyEnv, "passphrase")
+	_, err := KeyFromEnv()
+	tt.True(t, errors.Is(err, ErrKey))
+
+	_, err = Encrypt("pass", "pass", []byte("passphrase"))
+	tt.Equal(t, ErrKey, err)
+	// 16 bytes
+	_, err = Encrypt("pass", "pass", []byte("AAAAAAAAAAAAAAAAAAAAAA=="))
+	tt.Equal(t, ErrKey, err)
+	tt.Equal(t, ErrKey, Decrypt(&Secrets{}, []byte("passphrase")))
+}
+
+func TestLoadDecrypt(t *testing.T) {
+	gen, err := GenKey()
+	tt.Nil(t, err)
+	path := filepath.Join(t.TempDir(), "key")
+	tt.Nil(t, os.WriteFile(path, []byte(gen+"\n"), 0600))
+	key, err := KeyFromFile(path)
+	tt.Nil(t, err)
+	tt.Equal(t, gen, string(key))
+
+	tt.Nil(t, os.WriteFile(path, []byte("file-key\n"), 0600))
+	_, err = KeyFromFile(path)
+	tt.True(t, errors.Is(err, ErrKey))
+
+	enc, err := Encrypt("test", "secret", key)
+	tt.Nil(t, err)
+
+	toml := Toml{}
+	err = Load(`test = "`+enc+`"`, &toml, WithEmbed(), WithDecrypt(key))
+	tt.Nil(t, err)
+	tt.Equal(t, "secret", toml.Test)
+
+	other, err := GenKey()
+	tt.Nil(t, err)
+	err = Load(`test = "`+enc+`"`, &toml, WithEmbed(), WithDecrypt([]byte(other)))
+	tt.NotNil(t, err)
+}
@@ -0,0 +1,347 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	textType     = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// field is a config field of a struct, embedded structs are flattened
+type field struct {
+	reflect.StructField
+	key string
+}
+
+// fieldKey returns the toml key of the struct field, or "" if it is skipped
+func fieldKey(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+
+	name := strings.Split(f.Tag.Get("toml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name
+}
+
+// fieldsOf returns the config fields of the struct type
+func fieldsOf(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag.Get("toml") == "" {
+			for _, sub := range fieldsOf(f.Type) {
+				sub.Index = append([]int{i}, sub.Index...)
+				fields = append(fields, sub)
+			}
+			continue
+		}
+
+		if key := fieldKey(f); key != "" {
+			fields = append(fields, field{StructField: f, key: key})
+		}
+	}
+	return fields
+}
+
+// indirect returns the type pointed to by t
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isTable reports whether t decodes from a toml table
+func isTable(t reflect.Type) bool {
+	t = indirect(t)
+	return t.Kind() == reflect.Struct && t != timeType &&
+		!reflect.PointerTo(t).Implements(textType)
+}
+
+// joinKey joins the toml key path
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// lookup returns the value of key in m, matched case-insensitively
+// if there is no exact match
+func lookup(m map[string]interface{}, key string) (interface{}, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// decodeMap decodes the map into config, a pointer to a struct or a map
+func decodeMap(m map[string]interface{}, config interface{}) error {
+	rv := reflect.ValueOf(config)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("conf: decode into non-pointer %T", config)
+	}
+
+	return assign(rv.Elem(), m, "")
+}
+
+// decodeStruct decodes the toml table m into the struct dst
+func decodeStruct(dst reflect.Value, m map[string]interface{}, path string) error {
+	for _, f := range fieldsOf(dst.Type()) {
+		val, ok := lookup(m, f.key)
+		if !ok {
+			continue
+		}
+
+		err := assign(dst.FieldByIndex(f.Index), val, joinKey(path, f.key))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assign sets dst to src, converting scalars and strings between types
+func assign(dst reflect.Value, src interface{}, path string) error {
+	if src == nil {
+		return nil
+	}
+
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assign(dst.Elem(), src, path)
+	}
+
+	if s, ok := src.(string); ok && dst.Kind() != reflect.String &&
+		dst.CanAddr() && dst.Addr().Type().Implements(textType) {
+		err := dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		if err != nil {
+			return fmt.Errorf("conf: %s: %v", path, err)
+		}
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	if dst.Kind() == reflect.Interface {
+		if !sv.Type().AssignableTo(dst.Type()) {
+			return typeError(path, src, dst.Type())
+		}
+		dst.Set(sv)
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		switch sv.Kind() {
+		case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16,
+			reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+			reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+			dst.SetString(fmt.Sprint(src))
+			return nil
+		}
+
+	case reflect.Bool:
+		switch sv.Kind() {
+		case reflect.Bool:
+			dst.SetBool(sv.Bool())
+			return nil
+		case reflect.String:
+			b, err := strconv.ParseBool(strings.TrimSpace(sv.String()))
+			if err == nil {
+				dst.SetBool(b)
+				return nil
+			}
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if dst.Type() == durationType && sv.Kind() == reflect.String {
+			d, err := time.ParseDuration(strings.TrimSpace(sv.String()))
+			if err != nil {
+				return fmt.Errorf("conf: %s: %v", path, err)
+			}
+			dst.SetInt(int64(d))
+			return nil
+		}
+		if n, ok := toInt(sv); ok && !dst.OverflowInt(n) {
+			dst.SetInt(n)
+			return nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, ok := toInt(sv); ok && n >= 0 && !dst.OverflowUint(uint64(n)) {
+			dst.SetUint(uint64(n))
+			return nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if f, ok := toFloat(sv); ok {
+			dst.SetFloat(f)
+			return nil
+		}
+
+	case reflect.Slice, reflect.Array:
+		return assignList(dst, sv, path)
+
+	case reflect.Map:
+		if sv.Kind() != reflect.Map || dst.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), sv.Len()))
+		}
+		iter := sv.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if old := dst.MapIndex(reflect.ValueOf(key).Convert(dst.Type().Key())); old.IsValid() {
+				elem.Set(old)
+			}
+			if err := assign(elem, iter.Value().Interface(), joinKey(path, key)); err != nil {
+				return err
+			}
+			dst.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+		}
+		return nil
+
+	case reflect.Struct:
+		if dst.Type() == timeType {
+			return assignTime(dst, src, path)
+		}
+		if m, ok := src.(map[string]interface{}); ok {
+			return decodeStruct(dst, m, path)
+		}
+	}
+
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+	return typeError(path, src, dst.Type())
+}
+
+// assignList sets the slice or array dst to the list sv, a string is
+// split on commas
+func assignList(dst, sv reflect.Value, path string) error {
+	if sv.Kind() == reflect.String {
+		if dst.Type().Elem().Kind() == reflect.Uint8 {
+			sv = reflect.ValueOf([]byte(sv.String()))
+		} else {
+			parts := []interface{}{}
+			for _, s := range strings.Split(sv.String(), ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					parts = append(parts, s)
+				}
+			}
+			sv = reflect.ValueOf(parts)
+		}
+	}
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		return typeError(path, sv.Interface(), dst.Type())
+	}
+
+	n := sv.Len()
+	if dst.Kind() == reflect.Array {
+		if n > dst.Len() {
+			return fmt.Errorf("conf: %s: %d items overflow %s", path, n, dst.Type())
+		}
+	} else {
+		dst.Set(reflect.MakeSlice(dst.Type(), n, n))
+	}
+
+	for i := 0; i < n; i++ {
+		key := path + "[" + strconv.Itoa(i) + "]"
+		if err := assign(dst.Index(i), sv.Index(i).Interface(), key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignTime sets the time.Time dst from a time, a local toml time or
+// an RFC 3339 string
+func assignTime(dst reflect.Value, src interface{}, path string) error {
+	switch t := src.(type) {
+	case time.Time:
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	case interface {
+		AsTime(*time.Location) time.Time
+	}:
+		dst.Set(reflect.ValueOf(t.AsTime(time.Local)))
+		return nil
+	case string:
+		tm, err := time.Parse(time.RFC3339, strings.TrimSpace(t))
+		if err != nil {
+			return fmt.Errorf("conf: %s: %v", path, err)
+		}
+		dst.Set(reflect.ValueOf(tm))
+		return nil
+	}
+	return typeError(path, src, dst.Type())
+}
+
+// toInt converts the number or numeric string v to an int64
+func toInt(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), v.Uint() <= 1<<63-1
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return int64(f), f == float64(int64(f))
+	case reflect.String:
+		n, err := strconv.ParseInt(strings.TrimSpace(v.String()), 0, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// toFloat converts the number or numeric string v to a float64
+func toFloat(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v.String()), 64)
+		return f, err == nil
+	}
+
+	n, ok := toInt(v)
+	return float64(n), ok
+}
+
+func typeError(path string, src interface{}, t reflect.Type) error {
+	return fmt.Errorf("conf: %s: cannot decode %T into %s", path, src, t)
+}
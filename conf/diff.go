@@ -0,0 +1,144 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change a config value changed by a reload, Old or New is nil when
+// the map key or the pointer is not set
+type Change struct {
+	Path string
+	Old  interface{}
+	New  interface{}
+	// Restart the field, or a table holding it, is tagged
+	// `reload:"restart"`, the change is not applied live
+	Restart bool
+}
+
+func (c Change) String() string {
+	s := fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+	if c.Restart {
+		s += " (restart)"
+	}
+	return s
+}
+
+// Diff returns the changes of the values from the old to the new config,
+// of the same type, sorted by key path. Tables and maps are walked key
+// by key, arrays change as a whole.
+func Diff(old, new interface{}) []Change {
+	var changes []Change
+	diff(reflect.ValueOf(old), reflect.ValueOf(new), "", false, &changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// NeedsRestart reports whether a change is tagged `reload:"restart"`
+func NeedsRestart(changes []Change) bool {
+	for _, c := range changes {
+		if c.Restart {
+			return true
+		}
+	}
+	return false
+}
+
+// under reports whether the key path is the table path or under it
+func under(key, path string) bool {
+	return path == "" || key == path ||
+		strings.HasPrefix(key, path+".") || strings.HasPrefix(key, path+"[")
+}
+
+// diff appends the changes from a to b under path
+func diff(a, b reflect.Value, path string, restart bool, changes *[]Change) {
+	a, b = deref(a), deref(b)
+	t := typeOf(a, b)
+	switch {
+	case t == nil:
+		return
+	case isTable(t) && (!a.IsValid() || a.Type() == t) && (!b.IsValid() || b.Type() == t):
+		a, b = orZero(a, t), orZero(b, t)
+		for _, f := range fieldsOf(t) {
+			diff(a.FieldByIndex(f.Index), b.FieldByIndex(f.Index), joinKey(path, f.key),
+				restart || f.Tag.Get("reload") == "restart", changes)
+		}
+		return
+	case t.Kind() == reflect.Map && (!a.IsValid() || a.Type() == t) && (!b.IsValid() || b.Type() == t):
+		keys := map[string]reflect.Value{}
+		for _, m := range []reflect.Value{a, b} {
+			if m.IsValid() {
+				for _, k := range m.MapKeys() {
+					keys[fmt.Sprint(k.Interface())] = k
+				}
+			}
+		}
+		for name, k := range keys {
+			diff(mapIndex(a, k), mapIndex(b, k), joinKey(path, name), restart, changes)
+		}
+		return
+	}
+
+	if a.IsValid() && b.IsValid() && reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return
+	}
+	*changes = append(*changes, Change{Path: path, Old: valueOf(a),
+		New: valueOf(b), Restart: restart})
+}
+
+// deref returns the value pointed to by v, invalid for nil
+func deref(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		v = v.Elem()
+	}
+	return v
+}
+
+// typeOf returns the type of the valid value, nil if both are invalid
+func typeOf(a, b reflect.Value) reflect.Type {
+	if a.IsValid() {
+		return a.Type()
+	}
+	if b.IsValid() {
+		return b.Type()
+	}
+	return nil
+}
+
+// orZero returns v, or the zero value of the type if it is invalid
+func orZero(v reflect.Value, t reflect.Type) reflect.Value {
+	if v.IsValid() {
+		return v
+	}
+	return reflect.New(t).Elem()
+}
+
+// mapIndex returns the map value of the key, invalid if m is
+func mapIndex(m, k reflect.Value) reflect.Value {
+	if !m.IsValid() {
+		return m
+	}
+	return m.MapIndex(k)
+}
+
+// valueOf returns the interface of v, nil if it is invalid
+func valueOf(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -0,0 +1,100 @@
+package conf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vcaesar/tt"
+)
+
+type DiffConf struct {
+	Log struct {
+		Level string `toml:"level"`
+	} `toml:"log"`
+	DB struct {
+		Addr string `toml:"addr" reload:"restart"`
+		Pool *struct {
+			Max int `toml:"max"`
+		} `toml:"pool"`
+	} `toml:"db"`
+	Server struct {
+		Port  int               `toml:"port"`
+		Hosts []string          `toml:"hosts"`
+		Meta  map[string]string `toml:"meta"`
+	} `toml:"server" reload:"restart"`
+}
+
+func TestDiff(t *testing.T) {
+	old, cur := DiffConf{}, DiffConf{}
+	tt.Equal(t, 0, len(Diff(&old, &cur)))
+
+	old.Log.Level = "info"
+	old.DB.Addr = "db:1"
+	old.Server.Hosts = []string{"a"}
+	old.Server.Meta = map[string]string{"a": "1", "b": "2"}
+
+	cur.Log.Level = "debug"
+	cur.DB.Addr = "db:1"
+	cur.DB.Pool = &struct {
+		Max int `toml:"max"`
+	}{Max: 20}
+	cur.Server.Port = 80
+	cur.Server.Hosts = []string{"a", "b"}
+	cur.Server.Meta = map[string]string{"a": "1", "c": "3"}
+
+	changes := Diff(&old, &cur)
+	tt.Equal(t, []Change{
+		{Path: "db.pool.max", Old: 0, New: 20},
+		{Path: "log.level", Old: "info", New: "debug"},
+		{Path: "server.hosts", Old: []string{"a"}, New: []string{"a", "b"}, Restart: true},
+		{Path: "server.meta.b", Old: "2", New: nil, Restart: true},
+		{Path: "server.meta.c", Old: nil, New: "3", Restart: true},
+		{Path: "server.port", Old: 0, New: 80, Restart: true},
+	}, changes)
+	tt.True(t, NeedsRestart(changes))
+	tt.False(t, NeedsRestart(changes[:2]))
+	tt.Equal(t, "log.level: info -> debug", changes[1].String())
+	tt.Equal(t, "server.port: 0 -> 80 (restart)", changes[5].String())
+
+	m := map[string]interface{}{"a": map[string]interface{}{"b": int64(1)}}
+	n := map[string]interface{}{"a": map[string]interface{}{"b": int64(2)}}
+	tt.Equal(t, []Change{{Path: "a.b", Old: int64(1), New: int64(2)}}, Diff(m, n))
+}
+
+func TestOnPathChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf.toml")
+	tt.Nil(t, os.WriteFile(path, []byte("[log]\nlevel = \"info\"\n[db]\naddr = \"db:1\""), 0644))
+
+	w, err := WatchFile[DiffConf](context.Background(), path, WithDebounce(20*time.Millisecond))
+	tt.Nil(t, err)
+	defer w.Close()
+
+	levels := make(chan Change, 16)
+	w.OnPathChange("log.level", func(c Change) {
+		levels <- c
+	})
+	dbs := make(chan Change, 16)
+	w.OnPathChange("db", func(c Change) {
+		dbs <- c
+	})
+	diffs := make(chan []Change, 16)
+	w.OnDiff(func(changes []Change) {
+		diffs <- changes
+	})
+
+	tt.Nil(t, WriteFile(path, []byte("[log]\nlevel = \"debug\"\n[db]\naddr = \"db:2\"")))
+	tt.Equal(t, Change{Path: "log.level", Old: "info", New: "debug"}, wait(t, levels))
+	tt.Equal(t, Change{Path: "db.addr", Old: "db:1", New: "db:2", Restart: true}, wait(t, dbs))
+	tt.Equal(t, 2, len(wait(t, diffs)))
+
+	// a reload without changes calls no diff callbacks
+	tt.Nil(t, w.Reload())
+	select {
+	case changes := <-diffs:
+		t.Fatal("unexpected changes", changes)
+	default:
+	}
+}
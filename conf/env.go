@@ -0,0 +1,101 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Env override the config fields from the environment variables,
+// named by the `env:"NAME"` tag or prefix plus the key path,
+// e.g. APP_SERVER_PORT for server.port with the APP prefix.
+//
+// Slices are comma-separated, durations use time.ParseDuration.
+func Env(config interface{}, prefix string) error {
+	rv := reflect.ValueOf(config)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("conf: env into non-pointer %T", config)
+	}
+	if indirect(rv.Type()).Kind() != reflect.Struct {
+		return fmt.Errorf("conf: env into non-struct %T", config)
+	}
+
+	_, err := envStruct(rv.Elem(), envPrefix(prefix), "")
+	return err
+}
+
+// EnvName returns the environment variable name of the key path
+func EnvName(prefix, path string) string {
+	return envPrefix(prefix) + envName(path)
+}
+
+// envPrefix returns the prefix with one trailing underscore
+func envPrefix(prefix string) string {
+	prefix = strings.TrimSuffix(prefix, "_")
+	if prefix != "" {
+		prefix += "_"
+	}
+	return prefix
+}
+
+// envStruct overrides the fields of the struct v, it reports
+// whether any field was set
+func envStruct(v reflect.Value, prefix, path string) (bool, error) {
+	if v.Kind() == reflect.Ptr {
+		if !v.IsNil() {
+			return envStruct(v.Elem(), prefix, path)
+		}
+
+		// only allocate nil tables that get a value
+		nv := reflect.New(v.Type().Elem())
+		set, err := envStruct(nv.Elem(), prefix, path)
+		if set {
+			v.Set(nv)
+		}
+		return set, err
+	}
+
+	set := false
+	for _, f := range fieldsOf(v.Type()) {
+		key := joinKey(path, f.key)
+		fv := v.FieldByIndex(f.Index)
+
+		name := f.Tag.Get("env")
+		if name == "-" {
+			continue
+		}
+		if name == "" && isTable(f.Type) {
+			ok, err := envStruct(fv, prefix, key)
+			if err != nil {
+				return set, err
+			}
+			set = set || ok
+			continue
+		}
+		if name == "" {
+			name = prefix + envName(key)
+		}
+
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := assign(fv, val, key); err != nil {
+			return set, fmt.Errorf("%v (from $%s)", err, name)
+		}
+		set = true
+	}
+
+	return set, nil
+}
@@ -0,0 +1,69 @@
+package conf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vcaesar/tt"
+)
+
+type EnvConf struct {
+	Name  string `toml:"name"`
+	Debug bool   `toml:"debug"`
+	Token string `toml:"token" env:"API_TOKEN"`
+
+	Server struct {
+		Port    int           `toml:"port"`
+		Hosts   []string      `toml:"hosts"`
+		Timeout time.Duration `toml:"timeout"`
+	} `toml:"server"`
+
+	TLS *struct {
+		Cert string `toml:"cert"`
+	} `toml:"tls"`
+	DB *struct {
+		Host string `toml:"host"`
+	} `toml:"db"`
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("APP_NAME", "gt")
+	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("API_TOKEN", "secret")
+	t.Setenv("APP_SERVER_PORT", "9000")
+	t.Setenv("APP_SERVER_HOSTS", "a, b,c")
+	t.Setenv("APP_SERVER_TIMEOUT", "5s")
+	t.Setenv("APP_TLS_CERT", "cert.pem")
+
+	c := EnvConf{}
+	err := Env(&c, "APP")
+	tt.Nil(t, err)
+
+	tt.Equal(t, "gt", c.Name)
+	tt.True(t, c.Debug)
+	tt.Equal(t, "secret", c.Token)
+	tt.Equal(t, 9000, c.Server.Port)
+	tt.Equal(t, []string{"a", "b", "c"}, c.Server.Hosts)
+	tt.Equal(t, 5*time.Second, c.Server.Timeout)
+	tt.Equal(t, "cert.pem", c.TLS.Cert)
+	tt.Nil(t, c.DB)
+
+	t.Setenv("APP_SERVER_PORT", "http")
+	tt.NotNil(t, Env(&c, "APP_"))
+
+	m := map[string]interface{}{}
+	tt.Equal(t, "conf: env into non-struct *map[string]interface {}", Env(&m, "APP").Error())
+	tt.NotNil(t, Load(`test = "a"`, &m, WithEmbed(), WithEnv("APP")))
+	tt.Nil(t, Load(`test = "a"`, &m, WithEmbed()))
+}
+
+func TestLoadEnv(t *testing.T) {
+	t.Setenv("APP_SERVER_PORT", "9000")
+
+	app := App{}
+	err := Load("../testdata/server.toml", &app, WithEnv("APP"))
+	tt.Nil(t, err)
+	tt.Equal(t, "localhost", app.Server.Host)
+	tt.Equal(t, 9000, app.Server.Port)
+	tt.Equal(t, "APP_SERVER_PORT", EnvName("APP", "server.port"))
+}
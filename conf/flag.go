@@ -0,0 +1,323 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Flags the command-line flags of the config fields, the highest
+// priority config layer. A flag is named by the `flag:"name"` tag or
+// the key path, e.g. --server.port, and described by the `usage` tag.
+//
+// Parse the flags after loading the config from the files and the
+// environment, the explicitly set flags override them; WithFlags applies
+// them again on later loads.
+type Flags struct {
+	fs     *flag.FlagSet
+	config reflect.Value
+	fields []*flagField
+
+	prefix string
+	origin Origin
+	// err the config is not a pointer to a struct
+	err error
+}
+
+// flagField a config field bound to a flag
+type flagField struct {
+	flags *Flags
+	key   string
+	name  string
+	usage string
+	def   string
+	typ   reflect.Type
+	// path the field indexes of each table level from the config root
+	path [][]int
+
+	raw string
+	set bool
+}
+
+// NewFlags returns the flags of the config fields in a new flag set,
+// config is a pointer to a struct, otherwise no flag is defined and
+// Parse and Apply return the error
+func NewFlags(name string, config interface{}) *Flags {
+	return BindFlags(flag.NewFlagSet(name, flag.ContinueOnError), config)
+}
+
+// BindFlags define the flags of the config fields in the flag set and
+// set its Usage, config is a pointer to a struct like NewFlags
+func BindFlags(fs *flag.FlagSet, config interface{}) *Flags {
+	f := &Flags{fs: fs, config: reflect.ValueOf(config)}
+	if f.config.Kind() != reflect.Ptr || f.config.IsNil() {
+		f.err = fmt.Errorf("conf: flags of non-pointer %T", config)
+	} else if indirect(f.config.Type()).Kind() != reflect.Struct {
+		f.err = fmt.Errorf("conf: flags of non-struct %T", config)
+	} else {
+		f.bind(indirect(f.config.Type()), "", nil)
+	}
+
+	for _, field := range f.fields {
+		fs.Var(field, field.name, field.usage)
+	}
+	fs.Usage = f.PrintUsage
+	return f
+}
+
+// bind collect the flag fields of the struct type t
+func (f *Flags) bind(t reflect.Type, path string, index [][]int) {
+	for _, field := range fieldsOf(t) {
+		key := joinKey(path, field.key)
+		name := field.Tag.Get("flag")
+		if name == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([][]int{}, index...), field.Index)
+		if name == "" && isTable(field.Type) {
+			f.bind(indirect(field.Type), key, fieldIndex)
+			continue
+		}
+		if !flagType(field.Type) {
+			continue
+		}
+
+		if name == "" {
+			name = key
+		}
+		f.fields = append(f.fields, &flagField{
+			flags: f,
+			key:   key,
+			name:  name,
+			usage: field.Tag.Get("usage"),
+			def:   field.Tag.Get("default"),
+			typ:   field.Type,
+			path:  fieldIndex,
+		})
+	}
+}
+
+// flagType reports whether the type is set by a flag
+func flagType(t reflect.Type) bool {
+	t = indirect(t)
+	if t == timeType || reflect.PointerTo(t).Implements(textType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return flagType(t.Elem()) && !isTable(t.Elem())
+	case reflect.Map, reflect.Struct, reflect.Interface, reflect.Func, reflect.Chan:
+		return false
+	}
+	return true
+}
+
+// FlagSet returns the flag set
+func (f *Flags) FlagSet() *flag.FlagSet {
+	return f.fs
+}
+
+// Parse parse the command-line arguments, without the program name,
+// and set the config fields of the explicitly set flags
+func (f *Flags) Parse(args []string) error {
+	if f.err != nil {
+		return f.err
+	}
+	return f.fs.Parse(args)
+}
+
+// Sources set the environment variable prefix and the layer origin
+// reported as the value sources by PrintUsage
+func (f *Flags) Sources(prefix string, origin Origin) {
+	f.prefix, f.origin = prefix, origin
+}
+
+// Apply set the fields of config, a pointer to the bound struct type,
+// from the explicitly set flags
+func (f *Flags) Apply(config interface{}) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	rv := reflect.ValueOf(config)
+	if !rv.IsValid() || rv.Type() != f.config.Type() || rv.IsNil() {
+		return fmt.Errorf("conf: apply flags to %T, not %s", config, f.config.Type())
+	}
+	for _, field := range f.fields {
+		if !field.set {
+			continue
+		}
+		if err := assign(field.value(rv), field.raw, field.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Visit calls fn for the key path of each explicitly set flag
+func (f *Flags) Visit(fn func(key string)) {
+	for _, field := range f.fields {
+		if field.set {
+			fn(field.key)
+		}
+	}
+}
+
+// PrintUsage print the flags with their default, current value and
+// source to the output of the flag set
+func (f *Flags) PrintUsage() {
+	out := f.fs.Output()
+	if name := f.fs.Name(); name != "" {
+		fmt.Fprintf(out, "Usage of %s:\n", name)
+	} else {
+		fmt.Fprintf(out, "Usage:\n")
+	}
+
+	for _, field := range f.fields {
+		line := "  --" + field.name
+		if kind := field.kind(); kind != "bool" {
+			line += " " + kind
+		}
+		line += "\n    \t"
+		if field.usage != "" {
+			line += field.usage + " "
+		}
+
+		var info []string
+		if field.def != "" {
+			info = append(info, "default "+field.def)
+		}
+		info = append(info, "value "+field.String(), "source "+field.source())
+		fmt.Fprintln(out, line+"("+strings.Join(info, ", ")+")")
+	}
+}
+
+// source returns where the current value of the field comes from
+func (f *flagField) source() string {
+	if f.set {
+		return "flag"
+	}
+
+	env := f.flags.prefix != "" || f.envTag() != ""
+	if name := f.envName(); env && name != "" {
+		if _, ok := os.LookupEnv(name); ok {
+			return "env $" + name
+		}
+	}
+	if layer := f.flags.origin.Of(f.key); layer != "" {
+		return layer
+	}
+	if f.def != "" {
+		return "default"
+	}
+	return "unset"
+}
+
+// envTag returns the `env` tag of the field
+func (f *flagField) envTag() string {
+	t := indirect(f.flags.config.Type())
+	for i, index := range f.path {
+		sf := t.FieldByIndex(index)
+		if i == len(f.path)-1 {
+			return sf.Tag.Get("env")
+		}
+		t = indirect(sf.Type)
+	}
+	return ""
+}
+
+// envName returns the environment variable of the field, see Env
+func (f *flagField) envName() string {
+	if tag := f.envTag(); tag != "" && tag != "-" {
+		return tag
+	}
+	return EnvName(f.flags.prefix, f.key)
+}
+
+// value returns the field in the config root, allocating nil tables
+func (f *flagField) value(root reflect.Value) reflect.Value {
+	v := root
+	for _, index := range f.path {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.FieldByIndex(index)
+	}
+	return v
+}
+
+// kind returns the type name shown by the usage
+func (f *flagField) kind() string {
+	t := indirect(f.typ)
+	switch {
+	case t == durationType:
+		return "duration"
+	case t == timeType:
+		return "time"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return "list"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		return "int"
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uintptr:
+		return "uint"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "float"
+	case t.Kind() == reflect.Bool:
+		return "bool"
+	}
+	return "string"
+}
+
+// String returns the current value of the field
+func (f *flagField) String() string {
+	if f == nil || f.flags == nil {
+		return ""
+	}
+
+	v, ok := valueAt(f.flags.config, f.key)
+	if !ok {
+		return ""
+	}
+	v = reflect.Indirect(v)
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// Set set the config field from the flag value
+func (f *flagField) Set(s string) error {
+	// check the value before changing the config
+	if err := assign(reflect.New(f.typ).Elem(), s, f.key); err != nil {
+		return err
+	}
+
+	f.raw, f.set = s, true
+	return assign(f.value(f.flags.config), s, f.key)
+}
+
+// IsBoolFlag allows --name without a value for the bool fields
+func (f *flagField) IsBoolFlag() bool {
+	return indirect(f.typ).Kind() == reflect.Bool
+}
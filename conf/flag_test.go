@@ -0,0 +1,83 @@
+package conf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vcaesar/tt"
+)
+
+type FlagConf struct {
+	Name  string `toml:"name" usage:"the app name"`
+	Debug bool   `toml:"debug" flag:"d"`
+	Skip  string `toml:"skip" flag:"-"`
+
+	Server struct {
+		Host    string        `toml:"host" default:"localhost"`
+		Port    int           `toml:"port" default:"8080" usage:"the server port"`
+		Tags    []string      `toml:"tags"`
+		Timeout time.Duration `toml:"timeout"`
+	} `toml:"server"`
+	DB *struct {
+		User string `toml:"user"`
+	} `toml:"db"`
+}
+
+func TestFlags(t *testing.T) {
+	t.Setenv("APP_SERVER_HOST", "example.com")
+
+	c := FlagConf{}
+	origin, err := LoadLayers(&c, []Layer{{Name: "conf.toml",
+		Content: "name = \"gt\"\n[server]\nport = 80\ntimeout = 1000"}},
+		WithDefaults(), WithEnv("APP"))
+	tt.Nil(t, err)
+
+	flags := NewFlags("app", &c)
+	flags.Sources("APP", origin)
+	err = flags.Parse([]string{"--server.port", "9000", "-d",
+		"--server.tags=a,b", "--db.user", "root"})
+	tt.Nil(t, err)
+
+	tt.Equal(t, "gt", c.Name)
+	tt.True(t, c.Debug)
+	tt.Equal(t, "example.com", c.Server.Host)
+	tt.Equal(t, 9000, c.Server.Port)
+	tt.Equal(t, []string{"a", "b"}, c.Server.Tags)
+	tt.Equal(t, "root", c.DB.User)
+
+	var buf bytes.Buffer
+	flags.FlagSet().SetOutput(&buf)
+	flags.PrintUsage()
+	usage := buf.String()
+	tt.True(t, strings.HasPrefix(usage, "Usage of app:\n"))
+	tt.True(t, strings.Contains(usage, "  --name string\n    \tthe app name (value gt, source conf.toml)\n"))
+	tt.True(t, strings.Contains(usage, "  --d\n    \t(value true, source flag)\n"))
+	tt.True(t, strings.Contains(usage, "  --server.host string\n    \t(default localhost, value example.com, source env $APP_SERVER_HOST)\n"))
+	tt.True(t, strings.Contains(usage, "  --server.port int\n    \tthe server port (default 8080, value 9000, source flag)\n"))
+	tt.True(t, strings.Contains(usage, "  --server.timeout duration\n    \t(value 1µs, source conf.toml)\n"))
+	tt.False(t, strings.Contains(usage, "skip"))
+
+	tt.NotNil(t, flags.Parse([]string{"--server.port", "http"}))
+	tt.Equal(t, 9000, c.Server.Port)
+
+	reloaded := FlagConf{}
+	err = Load("[server]\nport = 80", &reloaded, WithEmbed(), WithFlags(flags))
+	tt.Nil(t, err)
+	tt.Equal(t, 9000, reloaded.Server.Port)
+	tt.Equal(t, "root", reloaded.DB.User)
+}
+
+func TestFlagsNonStruct(t *testing.T) {
+	m := map[string]interface{}{}
+	flags := NewFlags("app", &m)
+	err := flags.Parse([]string{"--name", "gt"})
+	tt.NotNil(t, err)
+	tt.Equal(t, "conf: flags of non-struct *map[string]interface {}", err.Error())
+	tt.NotNil(t, flags.Apply(&m))
+
+	tt.NotNil(t, NewFlags("app", FlagConf{}).Parse(nil))
+	tt.NotNil(t, NewFlags("app", &FlagConf{}).Apply(&m))
+	tt.NotNil(t, NewFlags("app", &FlagConf{}).Apply(nil))
+}
@@ -0,0 +1,269 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format the config file format
+type Format string
+
+// The config file formats
+const (
+	TOML   Format = "toml"
+	YAML   Format = "yaml"
+	JSON   Format = "json"
+	INI    Format = "ini"
+	Dotenv Format = "env"
+)
+
+// FormatOf returns the config format by the file extension,
+// unknown extensions are toml
+func FormatOf(path string) Format {
+	base := strings.ToLower(filepath.Base(path))
+	if base == ".env" || strings.HasPrefix(base, ".env.") {
+		return Dotenv
+	}
+
+	switch filepath.Ext(base) {
+	case ".yaml", ".yml":
+		return YAML
+	case ".json":
+		return JSON
+	case ".ini", ".cfg":
+		return INI
+	case ".env":
+		return Dotenv
+	}
+	return TOML
+}
+
+// decode decodes the data of the file in the format into config,
+// with strict the unknown keys are errors
+func decode(name string, data []byte, format Format, config interface{}, strict bool) error {
+	if format == TOML || format == "" {
+		return decodeFile(name, data, config, strict)
+	}
+
+	m, err := unmarshalMap(data, format)
+	if err != nil {
+		return err
+	}
+	if format == Dotenv {
+		m = nestEnv(m, reflect.TypeOf(config))
+	}
+
+	if strict {
+		if err = checkKeys(m, config, func(string) string { return name }); err != nil {
+			return err
+		}
+	}
+	return decodeMap(m, config)
+}
+
+// checkKeys returns the unknown keys of m in config as DecodeErrors,
+// the file of a key is returned by fileOf
+func checkKeys(m map[string]interface{}, config interface{}, fileOf func(string) string) error {
+	keys := unknownKeys(m, reflect.TypeOf(config), "")
+	if len(keys) == 0 {
+		return nil
+	}
+
+	errs := make(DecodeErrors, len(keys))
+	for i, key := range keys {
+		errs[i] = &DecodeError{File: fileOf(key), Key: key, Msg: "unknown key"}
+	}
+	return errs.sort()
+}
+
+// unmarshalMap decodes the data of the format into a toml like table
+func unmarshalMap(data []byte, format Format) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	switch format {
+	case YAML:
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+	case JSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&m); err != nil {
+			return nil, err
+		}
+		m = jsonNumbers(m).(map[string]interface{})
+	case INI:
+		return parseIni(data)
+	case Dotenv:
+		return parseEnv(data)
+	case TOML, "":
+		if err := unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("conf: unknown format %q", format)
+	}
+
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+	return m, nil
+}
+
+// jsonNumbers replaces the json.Number values in v with int64 or float64
+func jsonNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = jsonNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = jsonNumbers(e)
+		}
+	}
+	return v
+}
+
+// parseIni parses the ini data, [a.b] sections become nested tables
+func parseIni(data []byte) (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	table := root
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == ';' || text[0] == '#' {
+			continue
+		}
+
+		if text[0] == '[' {
+			if !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("conf: ini line %d: bad section %q", line, text)
+			}
+
+			table = root
+			for _, name := range strings.Split(text[1:len(text)-1], ".") {
+				name = strings.TrimSpace(name)
+				sub, ok := table[name].(map[string]interface{})
+				if !ok {
+					sub = map[string]interface{}{}
+					table[name] = sub
+				}
+				table = sub
+			}
+			continue
+		}
+
+		i := strings.IndexAny(text, "=:")
+		if i < 0 {
+			return nil, fmt.Errorf("conf: ini line %d: missing '=' in %q", line, text)
+		}
+		table[strings.TrimSpace(text[:i])] = unquote(strings.TrimSpace(text[i+1:]))
+	}
+
+	return root, scanner.Err()
+}
+
+// parseEnv parses the dotenv data into a flat table
+func parseEnv(data []byte) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+
+		i := strings.Index(text, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("conf: env line %d: missing '=' in %q", line, text)
+		}
+
+		val := strings.TrimSpace(text[i+1:])
+		if val != "" && val[0] != '"' && val[0] != '\'' {
+			if j := strings.Index(val, " #"); j >= 0 {
+				val = strings.TrimSpace(val[:j])
+			}
+		}
+		m[strings.TrimSpace(text[:i])] = unquote(val)
+	}
+
+	return m, scanner.Err()
+}
+
+// unquote removes the quotes around s, escapes are only
+// interpreted in double quotes
+func unquote(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+
+	switch {
+	case s[0] == '"' && s[len(s)-1] == '"':
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+		return s[1 : len(s)-1]
+	case s[0] == '\'' && s[len(s)-1] == '\'':
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// envName returns the environment variable name of the key
+func envName(key string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// nestEnv nests the flat KEY_NAME entries of m by the struct layout of t
+func nestEnv(m map[string]interface{}, t reflect.Type) map[string]interface{} {
+	t = indirect(t)
+	if t.Kind() != reflect.Struct {
+		return m
+	}
+
+	out := map[string]interface{}{}
+	for _, f := range fieldsOf(t) {
+		name := envName(f.key)
+		sub := map[string]interface{}{}
+		for k, v := range m {
+			k = envName(k)
+			if k == name {
+				out[f.key] = v
+			} else if isTable(f.Type) && strings.HasPrefix(k, name+"_") {
+				sub[k[len(name)+1:]] = v
+			}
+		}
+
+		if len(sub) > 0 {
+			out[f.key] = nestEnv(sub, f.Type)
+		}
+	}
+	return out
+}
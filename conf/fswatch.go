@@ -0,0 +1,143 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce the delay to collect a burst of file events into one
+// reload
+const DefaultDebounce = 100 * time.Millisecond
+
+// fileWatch watch the config files through their parent directories,
+// so atomic saves (write a temp file and rename it), removes and
+// symlink swaps like the Kubernetes ConfigMap ones keep being watched
+type fileWatch struct {
+	fsw   *fsnotify.Watcher
+	delay time.Duration
+
+	// files the watched files to their resolved symlink targets
+	files map[string]string
+	dirs  map[string]bool
+}
+
+func newFileWatch(delay time.Duration, paths ...string) (*fileWatch, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if delay <= 0 {
+		delay = DefaultDebounce
+	}
+	fw := &fileWatch{fsw: fsw, delay: delay, dirs: map[string]bool{}}
+	if err = fw.watch(paths...); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	return fw, nil
+}
+
+// watch set the watched files, adding the watches of their directories
+// and the directories of their symlink targets
+func (fw *fileWatch) watch(paths ...string) error {
+	files := map[string]string{}
+	dirs := map[string]bool{}
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+
+		target, _ := filepath.EvalSymlinks(abs)
+		files[abs] = target
+		dirs[filepath.Dir(abs)] = true
+		if target != "" {
+			dirs[filepath.Dir(target)] = true
+		}
+	}
+
+	for dir := range dirs {
+		// adding again re-adds the watch of a removed and recreated directory
+		if err := fw.fsw.Add(dir); err != nil {
+			return err
+		}
+	}
+	for dir := range fw.dirs {
+		if !dirs[dir] {
+			fw.fsw.Remove(dir)
+		}
+	}
+
+	fw.files, fw.dirs = files, dirs
+	return nil
+}
+
+// changed reports whether the event touches a watched file
+func (fw *fileWatch) changed(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+
+	name := filepath.Clean(event.Name)
+	for file, target := range fw.files {
+		if name == file || name == target {
+			return true
+		}
+
+		// the symlink, or a directory in the link chain, was swapped
+		now, _ := filepath.EvalSymlinks(file)
+		if now != target {
+			return true
+		}
+	}
+	return false
+}
+
+// run call reload after each debounced burst of changes until ctx is
+// done, reload returns the files to watch next
+func (fw *fileWatch) run(ctx context.Context, reload func() []string, report func(error)) {
+	defer fw.fsw.Close()
+
+	timer := time.NewTimer(fw.delay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-fw.fsw.Events:
+			if !ok {
+				return
+			}
+			if fw.changed(event) {
+				timer.Reset(fw.delay)
+			}
+		case err, ok := <-fw.fsw.Errors:
+			if !ok {
+				return
+			}
+			report(err)
+		case <-timer.C:
+			paths := reload()
+			// follow the new includes and symlink targets, re-add the removed watches
+			if err := fw.watch(paths...); err != nil {
+				report(err)
+			}
+		}
+	}
+}
@@ -14,31 +14,58 @@
 package conf
 
 import (
-	"log"
-	"os"
+	"bytes"
+	"errors"
+	"strings"
 
 	"github.com/pelletier/go-toml/v2"
 )
 
-// Init toml file config
-func Init(filePath string, config interface{}, embed1 ...bool) (err error) {
-	confLock.Lock()
-	var fileBytes []byte
-	if len(embed1) > 0 {
-		fileBytes = []byte(filePath)
-	} else {
-		fileBytes, err = os.ReadFile(filePath)
+// unmarshal decodes the toml data into config
+func unmarshal(data []byte, config interface{}) error {
+	return toml.Unmarshal(data, config)
+}
+
+// marshal encodes the config into toml
+func marshal(config interface{}) ([]byte, error) {
+	return toml.Marshal(config)
+}
+
+// decodeFile decodes the toml data of the file into config, returning
+// the errors as *DecodeError or DecodeErrors with their position.
+// With strict the unknown keys are errors.
+func decodeFile(name string, data []byte, config interface{}, strict bool) error {
+	dec := toml.NewDecoder(bytes.NewReader(data))
+	if strict {
+		dec.DisallowUnknownFields()
 	}
-	if err != nil {
-		log.Println("Toml init os.ReadFile error: ", err)
-		return err
+
+	err := dec.Decode(config)
+	if err == nil {
+		return nil
 	}
 
-	err = toml.Unmarshal(fileBytes, config)
-	if err != nil {
-		return err
+	var sme *toml.StrictMissingError
+	if errors.As(err, &sme) {
+		errs := make(DecodeErrors, 0, len(sme.Errors))
+		for _, e := range sme.Errors {
+			line, col := e.Position()
+			errs = append(errs, &DecodeError{File: name, Line: line, Column: col,
+				Key: strings.Join(e.Key(), "."), Msg: "unknown key"})
+		}
+		return errs.sort()
 	}
-	confLock.Unlock()
 
-	return nil
+	var de *toml.DecodeError
+	if errors.As(err, &de) {
+		line, col := de.Position()
+		key := strings.Join(de.Key(), ".")
+		if key == "" {
+			_, lines := tomlKeys(data)
+			key = lines[line]
+		}
+		return &DecodeError{File: name, Line: line, Column: col, Key: key,
+			Msg: strings.TrimPrefix(de.Error(), "toml: ")}
+	}
+	return err
 }
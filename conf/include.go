@@ -0,0 +1,217 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+)
+
+// IncludeKey the top-level config key listing the included files, e.g.
+// include = ["db.toml", "conf.d/*.toml"]. The paths are relative to the
+// including file and may be globs, matched in lexical order. The
+// included files are merged first, so the including file overrides them.
+const IncludeKey = "include"
+
+// table a decoded config table and the name of its source
+type table struct {
+	name string
+	m    map[string]interface{}
+}
+
+// hasInclude reports whether the config data has the include key
+func hasInclude(data []byte, format Format) bool {
+	if format == Dotenv {
+		return false
+	}
+
+	m, err := unmarshalMap(data, format)
+	if err != nil {
+		return false
+	}
+	_, ok := m[IncludeKey]
+	return ok
+}
+
+// readFile returns the content of the file in fsys, or in the OS
+// file system if fsys is nil
+func readFile(fsys fs.FS, name string) ([]byte, error) {
+	if fsys != nil {
+		return fs.ReadFile(fsys, name)
+	}
+	return os.ReadFile(name)
+}
+
+// fileID returns the path identifying the file in fsys, to detect
+// the include cycles
+func fileID(fsys fs.FS, name string) (string, error) {
+	if fsys != nil {
+		return pathpkg.Clean(name), nil
+	}
+	return filepath.Abs(name)
+}
+
+// readTables returns the tables of the config data read from path in
+// fsys, the working directory or the fsys root for contents: its
+// included files, recursively, then the data itself named name.
+// stack holds the including files.
+func readTables(fsys fs.FS, name, path string, data []byte, format Format, stack []string) ([]table, error) {
+	m, err := unmarshalMap(data, format)
+	if err != nil {
+		return nil, err
+	}
+	if format == Dotenv {
+		return []table{{name, m}}, nil
+	}
+
+	paths, err := includes(fsys, m, path)
+	if err != nil {
+		return nil, fmt.Errorf("conf: %s: %v", name, err)
+	}
+	if path != "" {
+		id, err := fileID(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		stack = append(stack, id)
+	}
+
+	var tables []table
+	for _, inc := range paths {
+		id, err := fileID(fsys, inc)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range stack {
+			if s == id {
+				return nil, fmt.Errorf("conf: include cycle: %s",
+					strings.Join(append(stack, id), " -> "))
+			}
+		}
+
+		data, err := readFile(fsys, inc)
+		if err != nil {
+			return nil, err
+		}
+		sub, err := readTables(fsys, inc, inc, data, FormatOf(inc), stack)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, sub...)
+	}
+
+	return append(tables, table{name, m}), nil
+}
+
+// includes removes the include key of the table m and returns the
+// included file paths in fsys, resolved against the directory of path
+func includes(fsys fs.FS, m map[string]interface{}, path string) ([]string, error) {
+	v, ok := m[IncludeKey]
+	if !ok {
+		return nil, nil
+	}
+	delete(m, IncludeKey)
+
+	var patterns []string
+	switch v := v.(type) {
+	case string:
+		patterns = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: %v is not a string", IncludeKey, item)
+			}
+			patterns = append(patterns, s)
+		}
+	default:
+		return nil, fmt.Errorf("%s: %T is not a string or an array", IncludeKey, v)
+	}
+
+	var paths []string
+	for _, p := range patterns {
+		switch {
+		case fsys != nil:
+			p = pathpkg.Join(pathpkg.Dir(path), p)
+		case !filepath.IsAbs(p):
+			p = filepath.Join(filepath.Dir(path), p)
+		}
+		if !strings.ContainsAny(p, "*?[") {
+			paths = append(paths, p)
+			continue
+		}
+
+		matches, err := glob(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", IncludeKey, err)
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// configFiles returns the config file at path and its included files,
+// only path if they can not be read
+func configFiles(path string, format Format) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []string{path}
+	}
+
+	tables, err := readTables(nil, path, path, data, format, nil)
+	if err != nil {
+		return []string{path}
+	}
+
+	files := make([]string, len(tables))
+	for i, t := range tables {
+		files[i] = t.name
+	}
+	return files
+}
+
+// glob returns the files in fsys matching the pattern, in lexical order
+func glob(fsys fs.FS, pattern string) ([]string, error) {
+	if fsys != nil {
+		return fs.Glob(fsys, pattern)
+	}
+	return filepath.Glob(pattern)
+}
+
+// LoadDir load every *.toml config file in the directory into config,
+// deep-merged in lexical order like LoadLayers. The directory is in
+// the WithFS file system if it is set.
+func LoadDir(dir string, config interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	pattern := filepath.Join(dir, "*.toml")
+	if o.fsys != nil {
+		pattern = pathpkg.Join(dir, "*.toml")
+	}
+
+	paths, err := glob(o.fsys, pattern)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("conf: no *.toml files in %s", dir)
+	}
+
+	layers := make([]Layer, len(paths))
+	for i, path := range paths {
+		layers[i] = Layer{FS: o.fsys, Path: path}
+	}
+	_, err = o.loadLayers(config, layers)
+	return err
+}
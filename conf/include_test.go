@@ -0,0 +1,150 @@
+package conf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/vcaesar/tt"
+)
+
+// writeFiles write the files under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		tt.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		tt.Nil(t, os.WriteFile(path, []byte(data), 0644))
+	}
+}
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"conf.toml": "include = [\"db.toml\", \"conf.d/*.toml\"]\ntest = \"root\"\n" +
+			"[server]\nhost = \"localhost\"",
+		"db.toml":          "test = \"db\"\n[server]\nport = 80",
+		"conf.d/a.toml":    "[server]\nport = 8080\ntags = [\"a\"]",
+		"conf.d/b.toml":    "include = \"../tags.yaml\"",
+		"tags.yaml":        "server:\n  tags: [b]",
+		"conf.d/skip.yaml": "test: skip",
+	})
+
+	path := filepath.Join(dir, "conf.toml")
+	app := App{}
+	tt.Nil(t, Load(path, &app, WithStrict()))
+	tt.Equal(t, "root", app.Test)
+	tt.Equal(t, "localhost", app.Server.Host)
+	tt.Equal(t, 8080, app.Server.Port)
+	tt.Equal(t, []string{"b"}, app.Server.Tags)
+
+	origin, err := LoadLayers(&App{}, []Layer{{Path: path}})
+	tt.Nil(t, err)
+	tt.Equal(t, filepath.Join(dir, "conf.d/a.toml"), origin.Of("server.port"))
+	tt.Equal(t, filepath.Join(dir, "tags.yaml"), origin.Of("server.tags"))
+	tt.Equal(t, path, origin.Of("test"))
+
+	tt.Equal(t, []string{
+		filepath.Join(dir, "db.toml"),
+		filepath.Join(dir, "conf.d/a.toml"),
+		filepath.Join(dir, "tags.yaml"),
+		filepath.Join(dir, "conf.d/b.toml"),
+		path,
+	}, configFiles(path, TOML))
+
+	toml := Toml{}
+	tt.Nil(t, Init(path, &toml))
+	tt.Equal(t, "root", toml.Test)
+
+	writeFiles(t, dir, map[string]string{"db.toml": "include = \"conf.toml\""})
+	err = Load(path, &app)
+	tt.NotNil(t, err)
+	tt.True(t, strings.Contains(err.Error(), "include cycle"))
+	tt.Equal(t, []string{path}, configFiles(path, TOML))
+
+	writeFiles(t, dir, map[string]string{"db.toml": "include = 1"})
+	tt.NotNil(t, Load(path, &app))
+	writeFiles(t, dir, map[string]string{"db.toml": "include = \"none.toml\""})
+	tt.NotNil(t, Load(path, &app))
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"10-base.toml": "test = \"base\"\n[server]\nport = 80\nhost = \"localhost\"",
+		"20-prod.toml": "[server]\nport = 8080",
+		"notes.txt":    "test = \"txt\"",
+	})
+
+	app := App{}
+	tt.Nil(t, LoadDir(dir, &app))
+	tt.Equal(t, "base", app.Test)
+	tt.Equal(t, "localhost", app.Server.Host)
+	tt.Equal(t, 8080, app.Server.Port)
+
+	tt.NotNil(t, LoadDir(t.TempDir(), &app))
+}
+
+func TestWatchInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"conf.toml":     "include = \"conf.d/*.toml\"",
+		"conf.d/a.toml": "test = \"v1\"",
+		"other/db.toml": "test = \"db\"",
+	})
+
+	path := filepath.Join(dir, "conf.toml")
+	w, err := WatchFile[Toml](context.Background(), path, WithDebounce(20*time.Millisecond))
+	tt.Nil(t, err)
+	defer w.Close()
+	tt.Equal(t, "v1", w.Get().Test)
+
+	changes := make(chan string, 16)
+	w.OnChange(func(old, new *Toml) {
+		changes <- new.Test
+	})
+
+	writeFiles(t, dir, map[string]string{"conf.d/a.toml": "test = \"v2\""})
+	tt.Equal(t, "v2", wait(t, changes))
+
+	// a new include is watched after the reload
+	writeFiles(t, dir, map[string]string{"conf.toml": "include = \"other/db.toml\""})
+	tt.Equal(t, "db", wait(t, changes))
+	writeFiles(t, dir, map[string]string{"other/db.toml": "test = \"db1\""})
+	tt.Equal(t, "db1", wait(t, changes))
+}
+
+func TestIncludeFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/conf.toml": {Data: []byte("include = [\"db.toml\", \"d/*.toml\"]\ntest = \"root\"")},
+		"conf/db.toml":   {Data: []byte("[server]\nport = 80\nhost = \"db\"")},
+		"conf/d/a.toml":  {Data: []byte("[server]\nport = 8080")},
+		"conf/d/b.toml":  {Data: []byte("include = \"../../tags.json\"")},
+		"tags.json":      {Data: []byte(`{"server": {"tags": ["a"]}}`)},
+	}
+
+	app := App{}
+	tt.Nil(t, LoadFS(fsys, "conf/conf.toml", &app, WithStrict()))
+	tt.Equal(t, "root", app.Test)
+	tt.Equal(t, "db", app.Server.Host)
+	tt.Equal(t, 8080, app.Server.Port)
+	tt.Equal(t, []string{"a"}, app.Server.Tags)
+
+	toml := Toml{}
+	tt.Nil(t, InitFS(fsys, "conf/conf.toml", &toml))
+	tt.Equal(t, "root", toml.Test)
+
+	app = App{}
+	tt.Nil(t, LoadDir("conf/d", &app, WithFS(fsys)))
+	tt.Equal(t, 8080, app.Server.Port)
+	tt.Equal(t, []string{"a"}, app.Server.Tags)
+
+	fsys["conf/db.toml"] = &fstest.MapFile{Data: []byte("include = \"conf.toml\"")}
+	err := LoadFS(fsys, "conf/conf.toml", &app)
+	tt.NotNil(t, err)
+	tt.True(t, strings.Contains(err.Error(), "include cycle: conf/conf.toml -> conf/db.toml -> conf/conf.toml"))
+}
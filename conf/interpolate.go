@@ -0,0 +1,359 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// InterpolationError a placeholder that can not be resolved
+type InterpolationError struct {
+	Key         string
+	Placeholder string
+	Err         error
+}
+
+func (e *InterpolationError) Error() string {
+	return "conf: " + e.Key + ": " + e.Placeholder + ": " + e.Err.Error()
+}
+
+func (e *InterpolationError) Unwrap() error {
+	return e.Err
+}
+
+// ErrUnresolved the placeholder value is not found
+var ErrUnresolved = errors.New("unresolved")
+
+// Interpolate resolve the placeholders in the string values of config,
+// a pointer to a struct or a decoded table:
+//
+//	${env:DB_PASS}       the environment variable
+//	${env:PORT:-8080}    the environment variable, or 8080 if unset or empty
+//	${file:/run/db}      the file content, without the trailing newline
+//	${ref:server.host}   the value of another key, resolved first
+//	$${                  a literal ${
+//
+// All the unresolved placeholders and reference cycles are returned as
+// *InterpolationError joined together. With dryRun the values are
+// left as they are, only the errors are reported.
+//
+// Only the string fields of a struct are resolved, WithInterpolate
+// resolves the config files before decoding them, so the placeholders
+// of the other field types are decoded from their values.
+func Interpolate(config interface{}, dryRun ...bool) error {
+	rv := reflect.ValueOf(config)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("conf: interpolate non-pointer %T", config)
+	}
+
+	r := &resolver{
+		root:      rv.Elem(),
+		strValues: collect(rv.Elem()),
+		done:      map[string]string{},
+		failed:    map[string]error{},
+		visiting:  map[string]bool{},
+	}
+
+	var errs []error
+	seen := map[error]bool{}
+	for _, s := range r.order {
+		val, err := r.resolve(s.key)
+		if err != nil {
+			// a failed reference is reported once, by the key it fails at
+			if !seen[err] {
+				seen[err] = true
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if len(dryRun) == 0 || !dryRun[0] {
+			s.set(val)
+		}
+	}
+
+	if len(dryRun) == 0 || !dryRun[0] {
+		for _, flush := range r.flush {
+			flush()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// strValue a string value of the config
+type strValue struct {
+	key string
+	val string
+	set func(string)
+}
+
+// strValues the string values of a config by key path, the values in
+// maps are stored back by flush
+type strValues struct {
+	strs  map[string]*strValue
+	order []*strValue
+	flush []func()
+}
+
+func (sv *strValues) add(s *strValue) {
+	sv.strs[s.key] = s
+	sv.order = append(sv.order, s)
+}
+
+// collect returns the string values of the config v
+func collect(v reflect.Value) *strValues {
+	sv := &strValues{strs: map[string]*strValue{}}
+	sv.collect(v, "")
+	return sv
+}
+
+func (sv *strValues) collect(v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			sv.collect(v.Elem(), path)
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		// a string of a decoded array is set through its interface
+		if elem := v.Elem(); elem.Kind() == reflect.String && v.CanSet() {
+			s := &strValue{key: path, val: elem.String(), set: func(str string) {
+				v.Set(reflect.ValueOf(str))
+			}}
+			sv.add(s)
+			return
+		}
+		sv.collect(v.Elem(), path)
+	case reflect.String:
+		if v.CanSet() {
+			s := &strValue{key: path, val: v.String(), set: v.SetString}
+			sv.add(s)
+		}
+	case reflect.Struct:
+		if !isTable(v.Type()) {
+			return
+		}
+		for _, f := range fieldsOf(v.Type()) {
+			sv.collect(v.FieldByIndex(f.Index), joinKey(path, f.key))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			sv.collect(v.Index(i), path+"["+strconv.Itoa(i)+"]")
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			key, elem := iter.Key(), iter.Value()
+			if elem.Kind() == reflect.Interface {
+				elem = elem.Elem()
+			}
+			if elem.Kind() != reflect.String {
+				// resolve in a settable copy, stored back once resolved
+				cp := reflect.New(iter.Value().Type()).Elem()
+				cp.Set(iter.Value())
+				sv.collect(cp, joinKey(path, key.String()))
+
+				m := v
+				sv.flush = append(sv.flush, func() { m.SetMapIndex(key, cp) })
+				continue
+			}
+
+			m := v
+			s := &strValue{key: joinKey(path, key.String()), val: elem.String()}
+			s.set = func(str string) {
+				m.SetMapIndex(key, reflect.ValueOf(str).Convert(m.Type().Elem()))
+			}
+			sv.add(s)
+		}
+	}
+}
+
+// resolver resolve the placeholders, following the references
+type resolver struct {
+	root reflect.Value
+	*strValues
+
+	done     map[string]string
+	failed   map[string]error
+	visiting map[string]bool
+	stack    []string
+}
+
+// resolve returns the resolved value of the string key
+func (r *resolver) resolve(key string) (string, error) {
+	if val, ok := r.done[key]; ok {
+		return val, nil
+	}
+	if err, ok := r.failed[key]; ok {
+		return "", err
+	}
+	if r.visiting[key] {
+		cycle := append(r.stack, key)
+		return "", &InterpolationError{Key: cycle[0], Placeholder: "${ref:" + key + "}",
+			Err: fmt.Errorf("reference cycle %s", strings.Join(cycle, " -> "))}
+	}
+
+	r.visiting[key] = true
+	r.stack = append(r.stack, key)
+	defer func() {
+		delete(r.visiting, key)
+		r.stack = r.stack[:len(r.stack)-1]
+	}()
+
+	val, err := r.expand(key, r.strs[key].val)
+	if err != nil {
+		r.failed[key] = err
+		return "", err
+	}
+	r.done[key] = val
+	return val, nil
+}
+
+// expand replace the placeholders in the value of key
+func (r *resolver) expand(key, s string) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i-1] + "${")
+			s = s[i+2:]
+			continue
+		}
+
+		end := strings.Index(s[i:], "}")
+		if end < 0 {
+			return "", &InterpolationError{Key: key, Placeholder: s[i:],
+				Err: errors.New("missing closing brace")}
+		}
+
+		placeholder := s[i : i+end+1]
+		val, err := r.lookup(s[i+2 : i+end])
+		if err != nil {
+			var ie *InterpolationError
+			if errors.As(err, &ie) {
+				return "", err
+			}
+			return "", &InterpolationError{Key: key, Placeholder: placeholder, Err: err}
+		}
+
+		b.WriteString(s[:i] + val)
+		s = s[i+end+1:]
+	}
+}
+
+// lookup returns the value of the placeholder body, source:name
+func (r *resolver) lookup(body string) (string, error) {
+	source, name, ok := strings.Cut(body, ":")
+	if !ok {
+		return "", errors.New("missing source, want env, file or ref")
+	}
+
+	switch source {
+	case "env":
+		name, def, hasDef := strings.Cut(name, ":-")
+		if val := os.Getenv(name); val != "" {
+			return val, nil
+		}
+		if hasDef {
+			return def, nil
+		}
+		return "", ErrUnresolved
+
+	case "file":
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+
+	case "ref":
+		if _, ok := r.strs[name]; ok {
+			return r.resolve(name)
+		}
+		v, ok := valueAt(r.root, name)
+		if !ok {
+			return "", ErrUnresolved
+		}
+		return fmt.Sprint(v.Interface()), nil
+	}
+
+	return "", fmt.Errorf("unknown source %q", source)
+}
+
+// valueAt returns the value at the key path, e.g. server.hosts[0]
+func valueAt(v reflect.Value, path string) (reflect.Value, bool) {
+	for path != "" {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+
+		if path[0] == '[' {
+			end := strings.Index(path, "]")
+			if end < 0 || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+				return v, false
+			}
+			i, err := strconv.Atoi(path[1:end])
+			if err != nil || i < 0 || i >= v.Len() {
+				return v, false
+			}
+			v, path = v.Index(i), strings.TrimPrefix(path[end+1:], ".")
+			continue
+		}
+
+		end := strings.IndexAny(path, ".[")
+		if end < 0 {
+			end = len(path)
+		}
+		key := path[:end]
+		path = strings.TrimPrefix(path[end:], ".")
+
+		switch v.Kind() {
+		case reflect.Struct:
+			found := false
+			for _, f := range fieldsOf(v.Type()) {
+				if strings.EqualFold(f.key, key) {
+					v, found = v.FieldByIndex(f.Index), true
+					break
+				}
+			}
+			if !found {
+				return v, false
+			}
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return v, false
+			}
+			v = v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return v, false
+			}
+		default:
+			return v, false
+		}
+	}
+	return v, v.IsValid()
+}
@@ -0,0 +1,118 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vcaesar/tt"
+)
+
+type Secrets struct {
+	Host  string            `toml:"host"`
+	Port  int               `toml:"port"`
+	URL   string            `toml:"url"`
+	Pass  string            `toml:"pass"`
+	Key   string            `toml:"key"`
+	Hosts []string          `toml:"hosts"`
+	Tags  map[string]string `toml:"tags"`
+	Raw   string            `toml:"raw"`
+}
+
+func TestInterpolate(t *testing.T) {
+	key := filepath.Join(t.TempDir(), "key")
+	tt.Nil(t, os.WriteFile(key, []byte("s3cret\n"), 0600))
+	t.Setenv("DB_PASS", "pass")
+
+	s := Secrets{
+		Host:  "${env:DB_HOST:-localhost}",
+		Port:  5432,
+		URL:   "pg://${ref:host}:${ref:port}/db",
+		Pass:  "${env:DB_PASS}",
+		Key:   "${file:" + key + "}",
+		Hosts: []string{"${ref:host}"},
+		Tags:  map[string]string{"db": "${ref:url}"},
+		Raw:   "$${env:DB_PASS}",
+	}
+	tt.Nil(t, Interpolate(&s))
+
+	tt.Equal(t, "localhost", s.Host)
+	tt.Equal(t, "pg://localhost:5432/db", s.URL)
+	tt.Equal(t, "pass", s.Pass)
+	tt.Equal(t, "s3cret", s.Key)
+	tt.Equal(t, []string{"localhost"}, s.Hosts)
+	tt.Equal(t, "pg://localhost:5432/db", s.Tags["db"])
+	tt.Equal(t, "${env:DB_PASS}", s.Raw)
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	s := Secrets{
+		Host: "${ref:url}",
+		URL:  "${ref:host}",
+		Pass: "${env:NO_SUCH_VAR}",
+		Key:  "${vault:db}",
+		Raw:  "${ref:port}-${ref:none}",
+	}
+	err := Interpolate(&s, true)
+	tt.NotNil(t, err)
+	tt.Equal(t, "${ref:url}", s.Host)
+
+	msgs := strings.Split(err.Error(), "\n")
+	tt.Equal(t, 4, len(msgs))
+	tt.Equal(t, "conf: host: ${ref:host}: reference cycle host -> url -> host", msgs[0])
+	tt.Equal(t, "conf: pass: ${env:NO_SUCH_VAR}: unresolved", msgs[1])
+	tt.Equal(t, `conf: key: ${vault:db}: unknown source "vault"`, msgs[2])
+	tt.Equal(t, "conf: raw: ${ref:none}: unresolved", msgs[3])
+
+	var ie *InterpolationError
+	tt.True(t, errors.As(err, &ie))
+	tt.True(t, errors.Is(err, ErrUnresolved))
+}
+
+func TestLoadInterpolate(t *testing.T) {
+	t.Setenv("APP_HOST", "example.com")
+
+	app := App{}
+	err := Load("test = \"${ref:server.host}:${ref:server.port}\"\n"+
+		"[server]\nhost = \"${env:APP_HOST}\"\nport = 80", &app, WithEmbed(), WithInterpolate())
+	tt.Nil(t, err)
+	tt.Equal(t, "example.com:80", app.Test)
+}
+
+type Typed struct {
+	Port    int           `toml:"port"`
+	Debug   bool          `toml:"debug"`
+	Timeout time.Duration `toml:"timeout"`
+	Hosts   []string      `toml:"hosts"`
+	Raw     string        `toml:"raw"`
+}
+
+func TestLoadInterpolateTyped(t *testing.T) {
+	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("APP_HOST", "example.com")
+
+	cfg := Typed{}
+	err := Load(`port = "${env:APP_PORT:-8080}"
+debug = "${env:APP_DEBUG}"
+timeout = "${env:APP_TIMEOUT:-5s}"
+hosts = ["${env:APP_HOST}", "${ref:port}"]
+raw = "$${env:APP_HOST}"`, &cfg, WithEmbed(), WithInterpolate())
+	tt.Nil(t, err)
+	tt.Equal(t, 8080, cfg.Port)
+	tt.True(t, cfg.Debug)
+	tt.Equal(t, 5*time.Second, cfg.Timeout)
+	tt.Equal(t, []string{"example.com", "8080"}, cfg.Hosts)
+	tt.Equal(t, "${env:APP_HOST}", cfg.Raw)
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	tt.Nil(t, os.WriteFile(path, []byte("port: ${env:APP_PORT:-9090}\n"), 0644))
+	cfg = Typed{}
+	tt.Nil(t, Load(path, &cfg, WithInterpolate()))
+	tt.Equal(t, 9090, cfg.Port)
+
+	err = Load(`port = "${env:NO_SUCH_VAR}"`, &cfg, WithEmbed(), WithInterpolate())
+	tt.True(t, errors.Is(err, ErrUnresolved))
+}
@@ -0,0 +1,219 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// ProfileEnv the environment variable choosing the profile config
+const ProfileEnv = "APP_PROFILE"
+
+// Layer a config source of LoadLayers
+type Layer struct {
+	// Name reported by Origin, the Path by default
+	Name string
+	Path string
+	// FS the file system of the Path and the included files,
+	// the OS file system by default
+	FS fs.FS
+	// Content the embedded config content, used instead of Path
+	Content string
+	// Format the config format, detected by the Path by default
+	Format Format
+	// Optional skip the layer if the file does not exist
+	Optional bool
+}
+
+// name returns the layer name
+func (l Layer) name() string {
+	if l.Name != "" {
+		return l.Name
+	}
+	if l.Path != "" {
+		return l.Path
+	}
+	return "embed"
+}
+
+// read returns the layer content, nil if an optional file does not exist
+func (l Layer) read() ([]byte, error) {
+	if l.Content != "" {
+		return []byte(l.Content), nil
+	}
+
+	data, err := readFile(l.FS, l.Path)
+	if l.Optional && errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// tables returns the tables of the layer and its included files,
+// nil if an optional file does not exist
+func (l Layer) tables() ([]table, error) {
+	data, err := l.read()
+	if data == nil || err != nil {
+		return nil, err
+	}
+
+	path := l.Path
+	if l.Content != "" {
+		path = ""
+	}
+	return readTables(l.FS, l.name(), path, data, l.format(), nil)
+}
+
+// format returns the layer config format
+func (l Layer) format() Format {
+	if l.Format != "" {
+		return l.Format
+	}
+	if l.Content != "" {
+		return TOML
+	}
+	return FormatOf(l.Path)
+}
+
+// Origin the name of the layer that supplied each key path
+type Origin map[string]string
+
+// Of returns the name of the layer that supplied the key path,
+// array items report the layer of the array
+func (o Origin) Of(key string) string {
+	for key != "" {
+		if name, ok := o[key]; ok {
+			return name
+		}
+
+		i := strings.LastIndexAny(key, ".[")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return ""
+}
+
+// Profile returns the layers of the base config path, the profile config
+// named by $APP_PROFILE and the optional local config, e.g.
+// conf.toml, conf.prod.toml and conf.local.toml.
+// The embedded default config contents go first.
+func Profile(path string, embed ...string) []Layer {
+	var layers []Layer
+	for _, content := range embed {
+		layers = append(layers, Layer{Name: "defaults", Content: content})
+	}
+	layers = append(layers, Layer{Path: path})
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	if profile := os.Getenv(ProfileEnv); profile != "" {
+		layers = append(layers, Layer{Path: base + "." + profile + ext})
+	}
+
+	return append(layers, Layer{Path: base + ".local" + ext, Optional: true})
+}
+
+// LoadLayers load the config layers into config in order, each one
+// deep-merges over the one before it: tables merge key by key and
+// arrays are replaced. It returns the layer that supplied each value,
+// the included files are reported by their path.
+func LoadLayers(config interface{}, layers []Layer, opts ...Option) (Origin, error) {
+	return newOptions(opts).loadLayers(config, layers)
+}
+
+// loadLayers load the config layers into config
+func (o *options) loadLayers(config interface{}, layers []Layer) (Origin, error) {
+	merged, origin, err := mergeLayers(layers, reflect.TypeOf(config))
+	if err != nil {
+		return nil, err
+	}
+
+	confLock.Lock()
+	defer confLock.Unlock()
+	if err := o.before(config); err != nil {
+		return nil, err
+	}
+	if o.interpolate {
+		if err := Interpolate(&merged); err != nil {
+			return nil, err
+		}
+	}
+	if o.strict {
+		if err := checkKeys(merged, config, origin.Of); err != nil {
+			return nil, err
+		}
+	}
+	if err := decodeMap(merged, config); err != nil {
+		return nil, err
+	}
+
+	return origin, o.after(config)
+}
+
+// mergeLayers returns the table merged from the layers and its origin,
+// the dotenv layers are nested by the struct type t
+func mergeLayers(layers []Layer, t reflect.Type) (map[string]interface{}, Origin, error) {
+	merged := map[string]interface{}{}
+	origin := Origin{}
+
+	for _, l := range layers {
+		tables, err := l.tables()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, tb := range tables {
+			m := tb.m
+			if l.format() == Dotenv && t != nil {
+				m = nestEnv(m, t)
+			}
+			merge(merged, m, "", tb.name, origin)
+		}
+	}
+
+	return merged, origin, nil
+}
+
+// merge deep-merges the table src over dst, recording the origin of
+// the values under path
+func merge(dst, src map[string]interface{}, path, name string, origin Origin) {
+	for k, v := range src {
+		key := joinKey(path, k)
+		sub, ok := v.(map[string]interface{})
+		if old, isMap := dst[k].(map[string]interface{}); ok && isMap {
+			merge(old, sub, key, name, origin)
+			continue
+		}
+
+		for o := range origin {
+			if o == key || strings.HasPrefix(o, key+".") {
+				delete(origin, o)
+			}
+		}
+		if ok {
+			// copy so later layers do not merge into this layer's table
+			table := map[string]interface{}{}
+			merge(table, sub, key, name, origin)
+			dst[k] = table
+			continue
+		}
+
+		dst[k] = v
+		origin[key] = name
+	}
+}
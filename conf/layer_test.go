@@ -0,0 +1,47 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+func TestLoadLayers(t *testing.T) {
+	t.Setenv(ProfileEnv, "prod")
+
+	layers := Profile("../testdata/layer/conf.toml", "test = \"default\"\n[server]\nport = 80")
+	tt.Equal(t, 4, len(layers))
+
+	app := App{}
+	origin, err := LoadLayers(&app, layers)
+	tt.Nil(t, err)
+
+	tt.Equal(t, "base", app.Test)
+	tt.Equal(t, "example.com", app.Server.Host)
+	tt.Equal(t, 8080, app.Server.Port)
+	tt.Equal(t, []string{"prod"}, app.Server.Tags)
+
+	tt.Equal(t, "../testdata/layer/conf.toml", origin.Of("server.port"))
+	tt.Equal(t, "../testdata/layer/conf.prod.toml", origin.Of("server.host"))
+	tt.Equal(t, "../testdata/layer/conf.prod.toml", origin.Of("server.tags[0]"))
+	tt.Equal(t, "", origin.Of("server.none"))
+}
+
+func TestLoadLocal(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "conf.toml")
+	tt.Nil(t, os.WriteFile(base, []byte("test = \"base\"\n[server]\nport = 80"), 0644))
+	tt.Nil(t, os.WriteFile(filepath.Join(dir, "conf.local.toml"), []byte("test = \"local\""), 0644))
+
+	app := App{}
+	origin, err := LoadLayers(&app, Profile(base))
+	tt.Nil(t, err)
+	tt.Equal(t, "local", app.Test)
+	tt.Equal(t, 80, app.Server.Port)
+	tt.Equal(t, filepath.Join(dir, "conf.local.toml"), origin.Of("test"))
+
+	_, err = LoadLayers(&app, []Layer{{Path: filepath.Join(dir, "none.toml")}})
+	tt.NotNil(t, err)
+}
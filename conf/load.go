@@ -0,0 +1,237 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"io/fs"
+	"time"
+)
+
+// Option the Load option
+type Option func(*options)
+
+type options struct {
+	format Format
+	embed  bool
+	fsys   fs.FS
+	strict bool
+
+	env    bool
+	prefix string
+	flags  *Flags
+
+	defaults    bool
+	validate    bool
+	interpolate bool
+	key         []byte
+
+	debounce time.Duration
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithFormat set the config format instead of detecting it
+// by the file extension
+func WithFormat(format Format) Option {
+	return func(o *options) {
+		o.format = format
+	}
+}
+
+// WithEmbed use the path argument as the config content,
+// it is toml unless WithFormat is set
+func WithEmbed() Option {
+	return func(o *options) {
+		o.embed = true
+	}
+}
+
+// WithFS read the config files, and the included files, from the file
+// system, e.g. an embed.FS, os.DirFS or fstest.MapFS
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fsys = fsys
+	}
+}
+
+// WithStrict reject the config keys without a struct field,
+// the errors are returned as DecodeErrors
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithEnv override the decoded config from the environment
+// variables with the prefix, see Env
+func WithEnv(prefix string) Option {
+	return func(o *options) {
+		o.env = true
+		o.prefix = prefix
+	}
+}
+
+// WithFlags apply the explicitly set flags after the environment
+// variables, see Flags
+func WithFlags(flags *Flags) Option {
+	return func(o *options) {
+		o.flags = flags
+	}
+}
+
+// WithDefaults fill the zero-valued fields from the `default` tag
+// before decoding, see Defaults
+func WithDefaults() Option {
+	return func(o *options) {
+		o.defaults = true
+	}
+}
+
+// WithValidate check the `validate` tag rules after decoding, see Validate
+func WithValidate() Option {
+	return func(o *options) {
+		o.validate = true
+	}
+}
+
+// WithDebounce set the delay collecting a burst of file events
+// into one reload of the watchers, DefaultDebounce by default
+func WithDebounce(d time.Duration) Option {
+	return func(o *options) {
+		o.debounce = d
+	}
+}
+
+// WithInterpolate resolve the ${...} placeholders of the config files
+// before decoding them into the config fields, so the placeholders of
+// the number, bool and duration fields are decoded from their values,
+// see Interpolate
+func WithInterpolate() Option {
+	return func(o *options) {
+		o.interpolate = true
+	}
+}
+
+// WithDecrypt decrypt the enc:v1: values with the key after decoding,
+// see Decrypt
+func WithDecrypt(key []byte) Option {
+	return func(o *options) {
+		o.key = key
+	}
+}
+
+// formatOf returns the format of the config at path
+func (o *options) formatOf(path string) Format {
+	if o.format != "" {
+		return o.format
+	}
+	if o.embed {
+		return TOML
+	}
+	return FormatOf(path)
+}
+
+// name returns the file name of the config at path in errors
+func (o *options) name(path string) string {
+	if o.embed {
+		return ""
+	}
+	return path
+}
+
+// read returns the config content at path
+func (o *options) read(path string) ([]byte, error) {
+	if o.embed {
+		return []byte(path), nil
+	}
+	return readFile(o.fsys, path)
+}
+
+// Load load the toml, yaml, json, ini or dotenv config file into config,
+// the decoder is picked by the file extension or WithFormat.
+// The included files are merged first, see IncludeKey.
+func Load(path string, config interface{}, opts ...Option) error {
+	o := newOptions(opts)
+	data, err := o.read(path)
+	if err != nil {
+		return err
+	}
+
+	// the placeholders are resolved in the decoded table
+	if format := o.formatOf(path); o.interpolate || hasInclude(data, format) {
+		l := Layer{Path: path, Format: format, FS: o.fsys}
+		if o.embed {
+			l = Layer{Name: "embed", Content: path, Format: format, FS: o.fsys}
+		}
+		_, err = o.loadLayers(config, []Layer{l})
+		return err
+	}
+
+	confLock.Lock()
+	defer confLock.Unlock()
+	if err = o.before(config); err != nil {
+		return err
+	}
+
+	err = decode(o.name(path), data, o.formatOf(path), config, o.strict)
+	if err != nil {
+		return err
+	}
+
+	return o.after(config)
+}
+
+// LoadFS load the config file name in the file system into config,
+// e.g. from an embed.FS, see Load
+func LoadFS(fsys fs.FS, name string, config interface{}, opts ...Option) error {
+	opts = append(opts[:len(opts):len(opts)], WithFS(fsys))
+	return Load(name, config, opts...)
+}
+
+// before runs the passes before decoding the config
+func (o *options) before(config interface{}) error {
+	if o.defaults {
+		return Defaults(config)
+	}
+	return nil
+}
+
+// after runs the passes over the decoded config
+func (o *options) after(config interface{}) error {
+	if o.env {
+		if err := Env(config, o.prefix); err != nil {
+			return err
+		}
+	}
+
+	if o.flags != nil {
+		if err := o.flags.Apply(config); err != nil {
+			return err
+		}
+	}
+
+	if o.key != nil {
+		if err := Decrypt(config, o.key); err != nil {
+			return err
+		}
+	}
+
+	if o.validate {
+		return Validate(config)
+	}
+	return nil
+}
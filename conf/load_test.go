@@ -0,0 +1,53 @@
+package conf
+
+import (
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+type Server struct {
+	Host string   `toml:"host"`
+	Port int      `toml:"port"`
+	Tags []string `toml:"tags"`
+}
+
+type App struct {
+	Test   string `toml:"test"`
+	Server Server `toml:"server"`
+}
+
+func TestLoad(t *testing.T) {
+	files := []string{"server.toml", "conf.yaml", "conf.json", "conf.ini", "conf.env"}
+	for _, name := range files {
+		app := App{}
+		err := Load("../testdata/"+name, &app)
+		tt.Nil(t, err, name)
+
+		tt.Equal(t, "conf", app.Test, name)
+		tt.Equal(t, "localhost", app.Server.Host, name)
+		tt.Equal(t, 8080, app.Server.Port, name)
+		tt.Equal(t, []string{"a", "b"}, app.Server.Tags, name)
+	}
+}
+
+func TestLoadEmbed(t *testing.T) {
+	app := App{}
+	err := Load("test: conf\nserver:\n  port: 80\n", &app, WithEmbed(), WithFormat(YAML))
+	tt.Nil(t, err)
+	tt.Equal(t, "conf", app.Test)
+	tt.Equal(t, 80, app.Server.Port)
+
+	toml := Toml{}
+	err = Load(conf1, &toml, WithEmbed())
+	tt.Nil(t, err)
+	tt.Equal(t, "conf", toml.Test)
+}
+
+func TestFormatOf(t *testing.T) {
+	tt.Equal(t, YAML, FormatOf("conf.yml"))
+	tt.Equal(t, JSON, FormatOf("a/conf.JSON"))
+	tt.Equal(t, Dotenv, FormatOf(".env.local"))
+	tt.Equal(t, INI, FormatOf("conf.ini"))
+	tt.Equal(t, TOML, FormatOf("conf"))
+}
@@ -0,0 +1,350 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultInterval the delay between the polls of a remote config
+	DefaultInterval = 30 * time.Second
+	// DefaultMaxBackoff the longest delay between the failed polls
+	DefaultMaxBackoff = 5 * time.Minute
+	// DefaultMaxSize the largest remote config body
+	DefaultMaxSize = 10 << 20
+)
+
+// ErrStale the remote config is loaded from the Cache file, the fetch
+// error is joined to it
+var ErrStale = errors.New("conf: stale remote config")
+
+// Remote a config source fetched over HTTP. The polls are conditional
+// on the ETag and Last-Modified of the last response, and the last body
+// decoded without error is kept in the Cache file, read when the
+// endpoint is down.
+type Remote struct {
+	URL string
+	// Format the config format, detected by the Content-Type,
+	// then by the URL path and the Cache name, by default
+	Format Format
+	// Cache the file keeping the last fetched config, its format is
+	// kept in the Cache + ".format" file
+	Cache string
+	// Interval the delay between the polls, DefaultInterval by default
+	Interval time.Duration
+	// MaxBackoff the longest delay between the failed polls, doubled
+	// from Interval on each failure, DefaultMaxBackoff by default
+	MaxBackoff time.Duration
+	// Client the http client, http.DefaultClient by default
+	Client *http.Client
+	// Header the extra request headers, e.g. Authorization
+	Header http.Header
+	// MaxSize the largest body in bytes, DefaultMaxSize by default
+	MaxSize int64
+
+	mu       sync.Mutex
+	body     []byte
+	format   Format
+	etag     string
+	modified string
+	// cached the content of the Cache file
+	cached []byte
+}
+
+// Fetch fetch the config, returning the body and whether it changed
+// since the last fetch. When the endpoint fails, the last body, or
+// the Cache file content with ErrStale, is returned with the error.
+func (r *Remote) Fetch(ctx context.Context) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.URL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	for k, v := range r.Header {
+		req.Header[k] = v
+	}
+
+	r.mu.Lock()
+	if r.etag != "" {
+		req.Header.Set("If-None-Match", r.etag)
+	}
+	if r.modified != "" {
+		req.Header.Set("If-Modified-Since", r.modified)
+	}
+	r.mu.Unlock()
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return r.fallback(err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.body, false, nil
+	default:
+		return r.fallback(fmt.Errorf("status %s", resp.Status))
+	}
+
+	maxSize := r.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return r.fallback(err)
+	}
+	if int64(len(data)) > maxSize {
+		return r.fallback(fmt.Errorf("config larger than %d bytes", maxSize))
+	}
+
+	r.mu.Lock()
+	changed := r.body == nil || !bytes.Equal(data, r.body)
+	r.body = data
+	r.format = formatOfType(resp.Header.Get("Content-Type"))
+	r.etag = resp.Header.Get("ETag")
+	r.modified = resp.Header.Get("Last-Modified")
+	r.mu.Unlock()
+	return data, changed, nil
+}
+
+// save write the config data to the Cache file, once it is decoded
+func (r *Remote) save(data []byte) error {
+	if r.Cache == "" {
+		return nil
+	}
+
+	format := r.formatOf()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cached != nil && bytes.Equal(data, r.cached) {
+		return nil
+	}
+	if err := WriteFile(r.Cache, data); err != nil {
+		return fmt.Errorf("conf: cache %s: %w", r.URL, err)
+	}
+	if err := WriteFile(r.Cache+".format", []byte(format)); err != nil {
+		return fmt.Errorf("conf: cache %s: %w", r.URL, err)
+	}
+	r.cached = data
+	return nil
+}
+
+// fallback returns the last body, or the Cache file content if
+// nothing was fetched yet, with the fetch error
+func (r *Remote) fallback(err error) ([]byte, bool, error) {
+	err = fmt.Errorf("conf: fetch %s: %w", r.URL, err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.body != nil || r.Cache == "" {
+		return r.body, false, err
+	}
+
+	data, cerr := os.ReadFile(r.Cache)
+	if cerr != nil {
+		return nil, false, err
+	}
+	// the format fetched with the cached config, if it was kept
+	format, _ := os.ReadFile(r.Cache + ".format")
+	r.body, r.format, r.cached = data, Format(strings.TrimSpace(string(format))), data
+	return data, true, fmt.Errorf("%w: %w", ErrStale, err)
+}
+
+// formatOf returns the format of the fetched config
+func (r *Remote) formatOf() Format {
+	if r.Format != "" {
+		return r.Format
+	}
+
+	r.mu.Lock()
+	format := r.format
+	r.mu.Unlock()
+	if format != "" {
+		return format
+	}
+
+	if u, err := url.Parse(r.URL); err == nil {
+		if format := extFormat(u.Path); format != "" {
+			return format
+		}
+	}
+	if format := extFormat(r.Cache); format != "" {
+		return format
+	}
+	return TOML
+}
+
+// extFormat returns the config format of the path, "" if its
+// extension is unknown
+func extFormat(path string) Format {
+	format := FormatOf(path)
+	if format == TOML && !strings.EqualFold(filepath.Ext(path), ".toml") {
+		return ""
+	}
+	return format
+}
+
+// formatOfType returns the config format of the Content-Type,
+// "" if it is unknown
+func formatOfType(contentType string) Format {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		return JSON
+	case strings.Contains(mt, "yaml"):
+		return YAML
+	case strings.Contains(mt, "toml"):
+		return TOML
+	}
+	return ""
+}
+
+// decode decodes the fetched config data into config
+func (r *Remote) decode(data []byte, config interface{}, opts []Option) error {
+	opts = append(opts[:len(opts):len(opts)], WithEmbed(), WithFormat(r.formatOf()))
+	return Load(string(data), config, opts...)
+}
+
+// LoadRemote fetch the remote config into config, from the Cache file
+// if the endpoint is down. The options are used like Load.
+// The config loaded from the Cache file is returned with the fetch
+// error matching ErrStale.
+func LoadRemote(ctx context.Context, r *Remote, config interface{}, opts ...Option) error {
+	data, _, err := r.Fetch(ctx)
+	if data == nil {
+		return err
+	}
+	if derr := r.decode(data, config, opts); derr != nil {
+		return derr
+	}
+	if err != nil {
+		return err
+	}
+	return r.save(data)
+}
+
+// WatchRemote load the remote config and poll it until Close is called
+// or ctx is done. The changes are decoded into a new *T and passed to
+// the OnChange callbacks like WatchFile, the fetch errors are sent to
+// Errors and back off the polls.
+func WatchRemote[T any](ctx context.Context, r *Remote, opts ...Option) (*Watcher[T], error) {
+	w := &Watcher[T]{
+		path: r.URL,
+		opts: opts,
+		errs: make(chan error, 16),
+		done: make(chan struct{}),
+	}
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	w.load = func(cfg *T) error {
+		data, _, err := r.Fetch(ctx)
+		if data == nil {
+			return err
+		}
+		if err != nil {
+			w.report(err)
+		}
+		if err = r.decode(data, cfg, opts); err != nil {
+			return err
+		}
+		if err = r.save(data); err != nil {
+			w.report(err)
+		}
+		return nil
+	}
+	if err := w.Reload(); err != nil {
+		w.cancel()
+		return nil, err
+	}
+
+	go func() {
+		defer close(w.done)
+		r.poll(ctx, func(data []byte) {
+			cfg := new(T)
+			if err := r.decode(data, cfg, opts); err != nil {
+				w.report(err)
+				return
+			}
+			w.swap(cfg)
+			if err := r.save(data); err != nil {
+				w.report(err)
+			}
+		}, w.report)
+	}()
+	return w, nil
+}
+
+// poll fetch the config every Interval until ctx is done, calling
+// reload with the changed data
+func (r *Remote) poll(ctx context.Context, reload func(data []byte), report func(error)) {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	maxBackoff := r.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	delay := interval
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		data, changed, err := r.Fetch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			report(err)
+			if delay *= 2; delay > maxBackoff {
+				delay = maxBackoff
+			}
+		} else {
+			delay = interval
+		}
+		if changed && data != nil {
+			reload(data)
+		}
+		timer.Reset(delay)
+	}
+}
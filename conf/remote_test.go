@@ -0,0 +1,168 @@
+package conf
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vcaesar/tt"
+)
+
+// configServer serves the config body with an ETag, or fails
+type configServer struct {
+	mu   sync.Mutex
+	body string
+	etag string
+	fail bool
+
+	hits, notModified int32
+}
+
+func (s *configServer) set(body, etag string, fail bool) {
+	s.mu.Lock()
+	s.body, s.etag, s.fail = body, etag, fail
+	s.mu.Unlock()
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&s.hits, 1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Header.Get("If-None-Match") == s.etag {
+		atomic.AddInt32(&s.notModified, 1)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("ETag", s.etag)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(s.body))
+}
+
+func TestRemote(t *testing.T) {
+	cs := &configServer{}
+	cs.set(`{"test": "v1", "server": {"port": 80}}`, `"1"`, false)
+	srv := httptest.NewServer(cs)
+	defer srv.Close()
+
+	cache := filepath.Join(t.TempDir(), "cache.json")
+	r := &Remote{URL: srv.URL + "/conf", Cache: cache}
+
+	app := App{}
+	tt.Nil(t, LoadRemote(context.Background(), r, &app))
+	tt.Equal(t, "v1", app.Test)
+	tt.Equal(t, 80, app.Server.Port)
+
+	data, changed, err := r.Fetch(context.Background())
+	tt.Nil(t, err)
+	tt.False(t, changed)
+	tt.Equal(t, `{"test": "v1", "server": {"port": 80}}`, string(data))
+	tt.Equal(t, int32(1), atomic.LoadInt32(&cs.notModified))
+
+	cached, err := os.ReadFile(cache)
+	tt.Nil(t, err)
+	tt.Equal(t, string(data), string(cached))
+
+	// the endpoint is down, a new process falls back to the cache
+	cs.set("", "", true)
+	app = App{}
+	err = LoadRemote(context.Background(), &Remote{URL: srv.URL, Cache: cache}, &app)
+	tt.True(t, errors.Is(err, ErrStale))
+	tt.Equal(t, "v1", app.Test)
+
+	// a broken config is not cached
+	cs.set(`{"test": `, `"2"`, false)
+	tt.NotNil(t, LoadRemote(context.Background(), r, &app))
+	cached, err = os.ReadFile(cache)
+	tt.Nil(t, err)
+	tt.Equal(t, string(data), string(cached))
+
+	cs.set(`{"test": "v2"}`, `"3"`, false)
+	err = LoadRemote(context.Background(), &Remote{URL: srv.URL, MaxSize: 8}, &app)
+	tt.NotNil(t, err)
+	tt.False(t, errors.Is(err, ErrStale))
+	tt.Nil(t, LoadRemote(context.Background(), &Remote{URL: srv.URL, MaxSize: 14}, &app))
+	tt.Equal(t, "v2", app.Test)
+
+	cs.set("", "", true)
+	tt.NotNil(t, LoadRemote(context.Background(), &Remote{URL: srv.URL}, &app))
+}
+
+func TestRemoteCacheFormat(t *testing.T) {
+	cs := &configServer{}
+	cs.set(`{"test": "v1"}`, `"1"`, false)
+	srv := httptest.NewServer(cs)
+	defer srv.Close()
+
+	cache := filepath.Join(t.TempDir(), "remote.cache")
+	app := App{}
+	tt.Nil(t, LoadRemote(context.Background(), &Remote{URL: srv.URL + "/conf", Cache: cache}, &app))
+
+	// the cache without an extension is decoded as the fetched json
+	cs.set("", "", true)
+	app = App{}
+	err := LoadRemote(context.Background(), &Remote{URL: srv.URL + "/conf", Cache: cache}, &app)
+	tt.True(t, errors.Is(err, ErrStale))
+	tt.Equal(t, "v1", app.Test)
+
+	// without the kept format, the URL path is used
+	tt.Nil(t, os.Remove(cache+".format"))
+	app = App{}
+	err = LoadRemote(context.Background(), &Remote{URL: srv.URL + "/conf.json", Cache: cache}, &app)
+	tt.True(t, errors.Is(err, ErrStale))
+	tt.Equal(t, "v1", app.Test)
+}
+
+func TestWatchRemote(t *testing.T) {
+	cs := &configServer{}
+	cs.set(`test = "v1"`, `"1"`, false)
+	srv := httptest.NewServer(cs)
+	defer srv.Close()
+
+	// the Format overrides the json Content-Type
+	r := &Remote{URL: srv.URL + "/conf.toml", Format: TOML,
+		Interval: 10 * time.Millisecond, MaxBackoff: 40 * time.Millisecond}
+	w, err := WatchRemote[Toml](context.Background(), r)
+	tt.Nil(t, err)
+	defer w.Close()
+	tt.Equal(t, "v1", w.Get().Test)
+
+	changes := make(chan string, 16)
+	w.OnChange(func(old, new *Toml) {
+		changes <- new.Test
+	})
+
+	cs.set(`test = "v2"`, `"2"`, false)
+	tt.Equal(t, "v2", wait(t, changes))
+
+	cs.set("", "", true)
+	tt.NotNil(t, wait(t, w.Errors()))
+	tt.Equal(t, "v2", w.Get().Test)
+
+	cs.set(`test = "v3"`, `"3"`, false)
+	tt.Equal(t, "v3", wait(t, changes))
+
+	tt.Nil(t, w.Close())
+	wait(t, w.Done())
+}
+
+func TestFormatOfType(t *testing.T) {
+	tt.Equal(t, JSON, formatOfType("application/json; charset=utf-8"))
+	tt.Equal(t, JSON, formatOfType("application/vnd.api+json"))
+	tt.Equal(t, YAML, formatOfType("application/x-yaml"))
+	tt.Equal(t, TOML, formatOfType("application/toml"))
+	tt.Equal(t, Format(""), formatOfType("text/plain"))
+	tt.Equal(t, YAML, (&Remote{URL: "http://localhost/conf.yaml"}).formatOf())
+}
@@ -0,0 +1,274 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// bareKey matches the toml keys written without quotes
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Sample returns a sample toml config of the config struct, each key
+// commented by its `doc`, `default` and `validate` tags and set to its
+// value in config, or to the default if it is zero. An empty array of
+// tables gets a commented-out example item.
+func Sample(config interface{}) ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(config))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("conf: sample of non-struct %T", config)
+	}
+
+	s := &sampler{seen: map[reflect.Type]bool{}}
+	if err := s.table(rv, ""); err != nil {
+		return nil, err
+	}
+	return []byte(s.String()), nil
+}
+
+// WriteSample write the sample toml config of the config struct to
+// the file, e.g. from a program run by go generate
+func WriteSample(path string, config interface{}) error {
+	data, err := Sample(config)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, data)
+}
+
+// sampler writes the sample config lines, prefix comments them out
+type sampler struct {
+	strings.Builder
+	prefix string
+	// first no line is written in the current section yet
+	first bool
+	// seen the struct types being written, to stop on recursive types
+	seen map[reflect.Type]bool
+}
+
+// entry a key of a sample table
+type entry struct {
+	key string
+	tag reflect.StructTag
+	v   reflect.Value
+}
+
+// entriesOf returns the keys of the struct or map v
+func entriesOf(v reflect.Value) []entry {
+	var entries []entry
+	if v.Kind() == reflect.Map {
+		for _, k := range v.MapKeys() {
+			entries = append(entries, entry{key: fmt.Sprint(k.Interface()), v: v.MapIndex(k)})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+		return entries
+	}
+
+	for _, f := range fieldsOf(v.Type()) {
+		entries = append(entries, entry{key: f.key, tag: f.Tag, v: v.FieldByIndex(f.Index)})
+	}
+	return entries
+}
+
+// sampleTable reports whether the value is written as a table
+func sampleTable(v reflect.Value) bool {
+	t := indirect(v.Type())
+	if t.Kind() == reflect.Interface && !v.IsNil() {
+		t = indirect(v.Elem().Type())
+	}
+	return isTable(t) || t.Kind() == reflect.Map
+}
+
+// tableItems reports whether the value is written as an array of tables
+func tableItems(v reflect.Value) bool {
+	t := indirect(v.Type())
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) &&
+		(isTable(t.Elem()) || indirect(t.Elem()).Kind() == reflect.Map)
+}
+
+// line writes a line of the current section
+func (s *sampler) line(text string) {
+	s.WriteString(strings.TrimRight(s.prefix+text, " ") + "\n")
+	s.first = false
+}
+
+// comments writes the comments of the field tags, after a blank line
+// unless it starts the section
+func (s *sampler) comments(tag reflect.StructTag) {
+	var lines []string
+	if doc := tag.Get("doc"); doc != "" {
+		lines = append(lines, strings.Split(doc, "\n")...)
+	}
+	if def, ok := tag.Lookup("default"); ok {
+		lines = append(lines, "default: "+def)
+	}
+	if rules := tag.Get("validate"); rules != "" {
+		lines = append(lines, "validate: "+rules)
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	if !s.first {
+		s.line("")
+	}
+	for _, l := range lines {
+		s.line("# " + l)
+	}
+}
+
+// header writes the table header after a blank line
+func (s *sampler) header(tag reflect.StructTag, header string) {
+	if s.Len() > 0 {
+		s.WriteString("\n")
+	}
+	s.first = true
+	s.comments(tag)
+	s.line(header)
+	s.first = true
+}
+
+// table writes the keys of the struct or map v, then its tables
+func (s *sampler) table(v reflect.Value, path string) error {
+	entries := entriesOf(v)
+	s.first = true
+	if v.Kind() == reflect.Struct {
+		s.seen[v.Type()] = true
+		defer delete(s.seen, v.Type())
+	}
+	for _, e := range entries {
+		if sampleTable(e.v) || tableItems(e.v) {
+			continue
+		}
+
+		key := joinKey(path, e.key)
+		ev := e.v
+		if def, ok := e.tag.Lookup("default"); ok && ev.IsZero() {
+			ev = reflect.New(ev.Type()).Elem()
+			if err := assign(ev, def, key); err != nil {
+				return err
+			}
+		}
+
+		val, ok, err := sampleValue(ev)
+		if err != nil {
+			return fmt.Errorf("conf: sample %s: %v", key, err)
+		}
+		if !ok {
+			continue
+		}
+		if iv := reflect.Indirect(ev); iv.IsValid() && iv.Type() == durationType {
+			d := time.Duration(iv.Int())
+			// nanoseconds are decoded by both toml backends
+			val += " # " + d.String()
+		}
+		s.comments(e.tag)
+		s.line(tomlKey(e.key) + " = " + val)
+	}
+
+	for _, e := range entries {
+		key := joinKey(path, tomlKey(e.key))
+		ev := e.v
+		if ev.Kind() == reflect.Interface && !ev.IsNil() {
+			ev = ev.Elem()
+		}
+
+		if ev.Kind() == reflect.Ptr && ev.IsNil() && s.seen[indirect(ev.Type())] {
+			// an unset recursive table
+			continue
+		}
+
+		switch {
+		case sampleTable(e.v):
+			s.header(e.tag, "["+key+"]")
+			if err := s.table(sampleItem(ev), key); err != nil {
+				return err
+			}
+		case tableItems(e.v):
+			ev = reflect.Indirect(ev)
+			if ev.Len() == 0 {
+				// a commented-out example item
+				prefix := s.prefix
+				s.prefix = "# " + prefix
+				s.header(e.tag, "[["+key+"]]")
+				err := s.table(sampleItem(reflect.New(ev.Type().Elem()).Elem()), key)
+				s.prefix = prefix
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			for i := 0; i < ev.Len(); i++ {
+				s.header(e.tag, "[["+key+"]]")
+				if err := s.table(sampleItem(ev.Index(i)), key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// sampleItem returns the struct or map of the table value v, a nil
+// table is replaced by its zero value
+func sampleItem(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.New(indirect(v.Type())).Elem()
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// sampleValue returns the toml value of v, false for nil values
+func sampleValue(v reflect.Value) (string, bool, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if v.Kind() == reflect.Interface {
+				return "", false, nil
+			}
+			v = reflect.New(v.Type().Elem())
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, ok, err := sampleValue(v.Index(i))
+			if err != nil {
+				return "", false, err
+			}
+			if ok {
+				items = append(items, item)
+			}
+		}
+		return "[" + strings.Join(items, ", ") + "]", true, nil
+	}
+
+	val, err := formatValue(v.Interface())
+	return val, err == nil, err
+}
+
+// tomlKey returns the key quoted unless it is a bare toml key
+func tomlKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
@@ -0,0 +1,62 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+func TestSample(t *testing.T) {
+	c := SchemaConf{Name: "gt", Labels: map[string]string{"b": "2", "a b": "1"}}
+	c.Server.Hosts = []string{"localhost"}
+
+	data, err := Sample(&c)
+	tt.Nil(t, err)
+	tt.Equal(t, `# the app name
+# validate: required
+name = "gt"
+
+# default: info
+# validate: oneof=debug|info|warn
+level = "info"
+
+# the http server
+[server]
+# default: 8080
+# validate: min=1,max=65535
+port = 8080
+
+# validate: min=1
+hosts = ["localhost"]
+
+# default: 5s
+timeout = 5000000000 # 5s
+
+[labels]
+"a b" = "1"
+b = "2"
+
+# [[users]]
+# name = ""
+#
+# # default: user
+# role = "user"
+`, string(data))
+
+	app := SchemaConf{}
+	tt.Nil(t, Load(string(data), &app, WithEmbed()))
+	tt.Equal(t, "gt", app.Name)
+	tt.Equal(t, uint16(8080), app.Server.Port)
+	tt.Equal(t, c.Labels, app.Labels)
+
+	path := filepath.Join(t.TempDir(), "conf.toml")
+	tt.Nil(t, WriteSample(path, &c))
+	written, err := os.ReadFile(path)
+	tt.Nil(t, err)
+	tt.Equal(t, string(data), string(written))
+
+	_, err = Sample(1)
+	tt.NotNil(t, err)
+}
@@ -0,0 +1,380 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Save write the config to the toml file atomically
+func Save(path string, config interface{}) error {
+	data, err := marshal(config)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, data)
+}
+
+// WriteFile write the data to a temp file and rename it over path, so
+// readers and watchers never see a half-written file.
+// The mode of an existing file is kept.
+func WriteFile(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, "."+base+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, mode)
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// Update change the values of the key paths in the toml file, keeping
+// its comments, key order and formatting. Missing keys are added to
+// their closest existing table, or to a new table appended to the file.
+// The keys in inline tables and arrays of tables can not be updated.
+// The file is written atomically, only if the result parses.
+func Update(path string, values map[string]interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	data, err = updateToml(data, values)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, data)
+}
+
+// updateToml returns the toml data with the values of the key paths changed
+func updateToml(data []byte, values map[string]interface{}) ([]byte, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	text := string(data)
+	for _, key := range keys {
+		val, err := formatValue(values[key])
+		if err != nil {
+			return nil, fmt.Errorf("conf: update %s: %v", key, err)
+		}
+
+		if err = checkKey(text, key); err != nil {
+			return nil, fmt.Errorf("conf: update %s: %v", key, err)
+		}
+		text, err = setKey(text, key, val)
+		if err != nil {
+			return nil, fmt.Errorf("conf: update %s: %v", key, err)
+		}
+	}
+
+	if _, err := unmarshalMap([]byte(text), TOML); err != nil {
+		return nil, fmt.Errorf("conf: update: invalid result: %v", err)
+	}
+	return []byte(text), nil
+}
+
+// checkKey returns an error if the key path of the toml text is in
+// an inline table, an array of tables or under a value
+func checkKey(text, key string) error {
+	m, err := unmarshalMap([]byte(text), TOML)
+	if err != nil {
+		return err
+	}
+	keys, _ := tomlKeys([]byte(text))
+
+	parts := strings.Split(key, ".")
+	for i := range parts[:len(parts)-1] {
+		path := joinParts(parts[:i+1])
+		switch v := m[parts[i]].(type) {
+		case nil:
+			return nil
+		case map[string]interface{}:
+			pos, ok := keys[path]
+			lines := strings.Split(text, "\n")
+			if ok && !strings.HasPrefix(strings.TrimSpace(lines[pos.line-1]), "[") {
+				return fmt.Errorf("%s is an inline table", path)
+			}
+			m = v
+		case []map[string]interface{}, []interface{}:
+			return fmt.Errorf("%s is an array", path)
+		default:
+			return fmt.Errorf("%s is not a table", path)
+		}
+	}
+	return nil
+}
+
+// formatValue returns the toml value of v, a scalar or an array
+func formatValue(v interface{}) (string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return "", fmt.Errorf("nil value")
+	}
+	if t, ok := rv.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339Nano), nil
+	}
+	if m, ok := rv.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return tomlString(string(text)), err
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return tomlString(rv.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		switch {
+		case math.IsNaN(f):
+			return "nan", nil
+		case math.IsInf(f, 1):
+			return "inf", nil
+		case math.IsInf(f, -1):
+			return "-inf", nil
+		}
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eEn") {
+			s += ".0"
+		}
+		return s, nil
+	case reflect.Slice, reflect.Array:
+		items := make([]string, rv.Len())
+		for i := range items {
+			item, err := formatValue(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case reflect.Map, reflect.Struct:
+		return "", fmt.Errorf("tables are not supported, update their keys")
+	}
+	return "", fmt.Errorf("can not encode %T", v)
+}
+
+// tomlString returns s quoted as a toml basic string
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// setKey returns the toml text with the value of key set to val
+func setKey(text, key, val string) (string, error) {
+	lines := strings.Split(text, "\n")
+	keys, lineKeys := tomlKeys([]byte(text))
+
+	if pos, ok := keys[key]; ok {
+		line := lines[pos.line-1]
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			return "", fmt.Errorf("is a table")
+		}
+
+		end, comment := valueEnd(lines, pos.line-1)
+		eq := assignIndex(line)
+		lines[pos.line-1] = line[:eq+1] + " " + val + comment
+		lines = append(lines[:pos.line], lines[end+1:]...)
+		return strings.Join(lines, "\n"), nil
+	}
+
+	// add the key after the last key of the closest existing table
+	table, name := "", key
+	for t := key; strings.Contains(t, "."); {
+		t = t[:strings.LastIndex(t, ".")]
+		if pos, ok := keys[t]; ok && strings.HasPrefix(strings.TrimSpace(lines[pos.line-1]), "[") {
+			table, name = t, key[len(t)+1:]
+			break
+		}
+	}
+
+	parts := strings.Split(key, ".")
+	if table == "" && len(parts) > 1 && !dotted(keys, lines, parts[0]) {
+		// a new table at the end of the file
+		text = strings.TrimRight(text, "\n")
+		if text != "" {
+			text += "\n\n"
+		}
+		return text + "[" + quoteKey(parts[:len(parts)-1]) + "]\n" +
+			quoteKey(parts[len(parts)-1:]) + " = " + val + "\n", nil
+	}
+
+	// the table section runs from its header to the next header
+	at, start := 0, 0
+	if table != "" {
+		start = keys[table].line
+		at = start
+	}
+	for i := start; i < len(lines); i++ {
+		if _, ok := lineKeys[i+1]; !ok {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "[") {
+			break
+		}
+
+		end, _ := valueEnd(lines, i)
+		at, i = end+1, end
+	}
+
+	line := quoteKey(strings.Split(name, ".")) + " = " + val
+	lines = append(lines[:at], append([]string{line}, lines[at:]...)...)
+	return strings.Join(lines, "\n"), nil
+}
+
+// dotted reports whether the top-level table is defined by the dotted
+// keys of the root table, e.g. a.b = 1, so its new keys are dotted keys
+// of the root table too
+func dotted(keys map[string]position, lines []string, table string) bool {
+	for key, pos := range keys {
+		if strings.HasPrefix(key, table+".") &&
+			!strings.HasPrefix(strings.TrimSpace(lines[pos.line-1]), "[") {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteKey returns the dotted toml key of the parts
+func quoteKey(parts []string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		quoted[i] = tomlKey(part)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// valueEnd returns the last line of the value of the key at line i,
+// and the comment after the value on that line
+func valueEnd(lines []string, i int) (int, string) {
+	line := lines[i]
+	eq := assignIndex(line)
+	if eq < 0 {
+		return i, ""
+	}
+
+	value := strings.TrimSpace(line[eq+1:])
+	for _, q := range []string{`"""`, "'''"} {
+		if strings.HasPrefix(value, q) && !strings.Contains(value[3:], q) {
+			for j := i + 1; j < len(lines); j++ {
+				if strings.Contains(lines[j], q) {
+					return j, comment(lines[j][strings.Index(lines[j], q)+3:])
+				}
+			}
+			return len(lines) - 1, ""
+		}
+	}
+
+	depth := brackets(value)
+	j := i
+	for depth > 0 && j+1 < len(lines) {
+		j++
+		depth += brackets(lines[j])
+	}
+	if j == i {
+		return i, comment(line[eq+1:])
+	}
+	return j, comment(lines[j])
+}
+
+// comment returns the trailing comment of the toml value s, with the
+// space before it
+func comment(s string) string {
+	quote := byte(0)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			j := i
+			for j > 0 && (s[j-1] == ' ' || s[j-1] == '\t') {
+				j--
+			}
+			if j == i {
+				return " " + s[i:]
+			}
+			return s[j:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+func TestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf.toml")
+	app := App{Test: "conf", Server: Server{Host: "localhost", Port: 80, Tags: []string{"a"}}}
+	tt.Nil(t, Save(path, &app))
+
+	saved := App{}
+	tt.Nil(t, Load(path, &saved))
+	tt.Equal(t, app, saved)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	tt.Nil(t, err)
+	tt.Equal(t, 1, len(entries))
+}
+
+const updateConf = `# the app config
+test = "conf" # the test name
+
+[server]
+# the server host
+host = "localhost"
+tags = [
+  "a",
+  "b", # the b tag
+]
+
+[db]
+user = "root"
+`
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf.toml")
+	tt.Nil(t, os.WriteFile(path, []byte(updateConf), 0600))
+
+	err := Update(path, map[string]interface{}{
+		"test":        "new",
+		"name":        "gt",
+		"server.port": 9000,
+		"server.tags": []string{"c"},
+		"db.pool.max": 20,
+	})
+	tt.Nil(t, err)
+
+	data, err := os.ReadFile(path)
+	tt.Nil(t, err)
+	tt.Equal(t, `# the app config
+test = "new" # the test name
+name = "gt"
+
+[server]
+# the server host
+host = "localhost"
+tags = ["c"]
+port = 9000
+
+[db]
+user = "root"
+pool.max = 20
+`, string(data))
+
+	tt.Nil(t, Update(path, map[string]interface{}{"log.level": "debug"}))
+	app := struct {
+		DB struct {
+			Pool struct {
+				Max int `toml:"max"`
+			} `toml:"pool"`
+		} `toml:"db"`
+		Log struct {
+			Level string `toml:"level"`
+		} `toml:"log"`
+	}{}
+	tt.Nil(t, Load(path, &app))
+	tt.Equal(t, 20, app.DB.Pool.Max)
+	tt.Equal(t, "debug", app.Log.Level)
+
+	fi, err := os.Stat(path)
+	tt.Nil(t, err)
+	tt.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	tt.NotNil(t, Update(path, map[string]interface{}{"server": 1}))
+	tt.NotNil(t, Update(path, map[string]interface{}{"db.pool": map[string]int{"max": 1}}))
+}
+
+func TestUpdateTables(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf.toml")
+	conf := "[a]\nb = { c = 1 }\nn = 1\n\n[[x]]\ny = 1\n\n[[x]]\ny = 2\n"
+	tt.Nil(t, os.WriteFile(path, []byte(conf), 0644))
+
+	err := Update(path, map[string]interface{}{"a.b.c": 2})
+	tt.Equal(t, "conf: update a.b.c: a.b is an inline table", err.Error())
+	err = Update(path, map[string]interface{}{"x.y": 3})
+	tt.Equal(t, "conf: update x.y: x is an array", err.Error())
+	err = Update(path, map[string]interface{}{"a.n.m": 3})
+	tt.Equal(t, "conf: update a.n.m: a.n is not a table", err.Error())
+
+	data, err := os.ReadFile(path)
+	tt.Nil(t, err)
+	tt.Equal(t, conf, string(data))
+
+	tt.Nil(t, Update(path, map[string]interface{}{"a.b": 3}))
+	m, err := unmarshalMap([]byte(updateResult(t, path)), TOML)
+	tt.Nil(t, err)
+	tt.Equal(t, int64(3), m["a"].(map[string]interface{})["b"])
+
+	tt.NotNil(t, Update(path, map[string]interface{}{"a.b": 4, "a": 1}))
+	data, err = updateToml([]byte("a = 1\n"), map[string]interface{}{"a": "\x00\t\"é\\"})
+	tt.Nil(t, err)
+	tt.Equal(t, `a = "\u0000\t\"é\\"`+"\n", string(data))
+	_, err = updateToml([]byte("[a]\nb = 1\n[a]\n"), map[string]interface{}{"a.c": 1})
+	tt.NotNil(t, err)
+}
+
+func updateResult(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	tt.Nil(t, err)
+	return string(data)
+}
+
+func TestUpdateKeys(t *testing.T) {
+	data, err := updateToml([]byte(`"a=b" = 1 # c = d`+"\n"), map[string]interface{}{"a=b": 2})
+	tt.Nil(t, err)
+	tt.Equal(t, `"a=b" = 2 # c = d`+"\n", string(data))
+
+	data, err = updateToml([]byte("a.b = 1\n\n[x]\ny = 1\n"), map[string]interface{}{"a.c": 2})
+	tt.Nil(t, err)
+	tt.Equal(t, "a.b = 1\na.c = 2\n\n[x]\ny = 1\n", string(data))
+
+	data, err = updateToml([]byte("[x]\ny = 1\n"), map[string]interface{}{"n.a b.c": "v"})
+	tt.Nil(t, err)
+	tt.Equal(t, "[x]\ny = 1\n\n[n.\"a b\"]\nc = \"v\"\n", string(data))
+	m, err := unmarshalMap(data, TOML)
+	tt.Nil(t, err)
+	tt.Equal(t, "v", m["n"].(map[string]interface{})["a b"].(map[string]interface{})["c"])
+}
@@ -0,0 +1,179 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SchemaURI the JSON Schema dialect of Schema
+const SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema returns the JSON Schema of the config struct, for the editor
+// completion and checks of the toml, yaml and json configs. The fields
+// are described by the `doc` tag, the `default` and `validate` tags
+// become the defaults, required keys, enums and bounds.
+func Schema(config interface{}) ([]byte, error) {
+	t := indirect(reflect.TypeOf(config))
+	s := schemaOf(t, map[reflect.Type]bool{})
+	s["$schema"] = SchemaURI
+	if t.Name() != "" {
+		s["title"] = t.Name()
+	}
+	if props, ok := s["properties"].(map[string]interface{}); ok {
+		props[IncludeKey] = map[string]interface{}{
+			"description": "the included config files, relative paths or globs",
+			"type":        []string{"string", "array"},
+			"items":       map[string]interface{}{"type": "string"},
+		}
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// WriteSchema write the JSON Schema of the config struct to the file,
+// e.g. from a program run by go generate
+func WriteSchema(path string, config interface{}) error {
+	data, err := Schema(config)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, data)
+}
+
+// schemaOf returns the schema of the type, seen holds the struct types
+// being described to stop on recursive types
+func schemaOf(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	t = indirect(t)
+	switch {
+	case t == durationType:
+		return map[string]interface{}{"type": []string{"string", "integer"}}
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case reflect.PointerTo(t).Implements(textType):
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object",
+			"additionalProperties": schemaOf(t.Elem(), seen)}
+	case reflect.Struct:
+	default:
+		return map[string]interface{}{}
+	}
+
+	if seen[t] {
+		return map[string]interface{}{"type": "object"}
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	props := map[string]interface{}{}
+	var required []string
+	for _, f := range fieldsOf(t) {
+		s := schemaOf(f.Type, seen)
+		if doc := f.Tag.Get("doc"); doc != "" {
+			s["description"] = doc
+		}
+		if def, ok := f.Tag.Lookup("default"); ok {
+			if v, ok := schemaValue(f.Type, def); ok {
+				s["default"] = v
+			}
+		}
+		if rules(f.Tag.Get("validate"), s, f.Type) {
+			required = append(required, f.key)
+		}
+		props[f.key] = s
+	}
+
+	s := map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// rules add the bounds and enums of the `validate` rules to the field
+// schema s, reporting whether the field is required
+func rules(tag string, s map[string]interface{}, t reflect.Type) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			required = true
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+
+			bound := map[string]string{"min": "minimum", "max": "maximum"}[name]
+			switch indirect(t).Kind() {
+			case reflect.String:
+				bound = map[string]string{"min": "minLength", "max": "maxLength"}[name]
+			case reflect.Slice, reflect.Array:
+				bound = map[string]string{"min": "minItems", "max": "maxItems"}[name]
+			case reflect.Map:
+				bound = map[string]string{"min": "minProperties", "max": "maxProperties"}[name]
+			}
+			s[bound] = n
+		case "oneof":
+			var enum []interface{}
+			for _, opt := range strings.Split(arg, "|") {
+				if v, ok := schemaValue(t, opt); ok {
+					enum = append(enum, v)
+				}
+			}
+			s["enum"] = enum
+		}
+	}
+	return required
+}
+
+// schemaValue returns the json value of the tag value s decoded as
+// the type t, durations, times and texts stay strings
+func schemaValue(t reflect.Type, s string) (interface{}, bool) {
+	it := indirect(t)
+	if it == durationType || it == timeType || reflect.PointerTo(it).Implements(textType) {
+		return s, true
+	}
+
+	v := reflect.New(it).Elem()
+	if err := assign(v, s, ""); err != nil {
+		return nil, false
+	}
+	return v.Interface(), true
+}
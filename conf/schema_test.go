@@ -0,0 +1,78 @@
+package conf
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vcaesar/tt"
+)
+
+type SchemaConf struct {
+	Name  string `toml:"name" doc:"the app name" validate:"required"`
+	Level string `toml:"level" default:"info" validate:"oneof=debug|info|warn"`
+
+	Server struct {
+		Port    uint16        `toml:"port" default:"8080" validate:"min=1,max=65535"`
+		Hosts   []string      `toml:"hosts" validate:"min=1"`
+		Timeout time.Duration `toml:"timeout" default:"5s"`
+	} `toml:"server" doc:"the http server"`
+
+	Labels map[string]string `toml:"labels"`
+	Users  []struct {
+		Name string `toml:"name"`
+		Role string `toml:"role" default:"user"`
+	} `toml:"users"`
+	Next *SchemaConf `toml:"next"`
+}
+
+func TestSchema(t *testing.T) {
+	data, err := Schema(&SchemaConf{})
+	tt.Nil(t, err)
+
+	s := map[string]interface{}{}
+	tt.Nil(t, json.Unmarshal(data, &s))
+	tt.Equal(t, SchemaURI, s["$schema"])
+	tt.Equal(t, "SchemaConf", s["title"])
+	tt.Equal(t, false, s["additionalProperties"])
+	tt.Equal(t, []interface{}{"name"}, s["required"])
+
+	props := s["properties"].(map[string]interface{})
+	name := props["name"].(map[string]interface{})
+	tt.Equal(t, "string", name["type"])
+	tt.Equal(t, "the app name", name["description"])
+
+	level := props["level"].(map[string]interface{})
+	tt.Equal(t, "info", level["default"])
+	tt.Equal(t, []interface{}{"debug", "info", "warn"}, level["enum"])
+
+	server := props["server"].(map[string]interface{})
+	tt.Equal(t, "the http server", server["description"])
+	sp := server["properties"].(map[string]interface{})
+	port := sp["port"].(map[string]interface{})
+	tt.Equal(t, "integer", port["type"])
+	tt.Equal(t, float64(8080), port["default"])
+	tt.Equal(t, float64(1), port["minimum"])
+	tt.Equal(t, float64(65535), port["maximum"])
+	tt.Equal(t, float64(1), sp["hosts"].(map[string]interface{})["minItems"])
+	tt.Equal(t, "5s", sp["timeout"].(map[string]interface{})["default"])
+
+	labels := props["labels"].(map[string]interface{})
+	tt.Equal(t, "object", labels["type"])
+	tt.Equal(t, "string", labels["additionalProperties"].(map[string]interface{})["type"])
+
+	users := props["users"].(map[string]interface{})
+	tt.Equal(t, "array", users["type"])
+	tt.Equal(t, "object", users["items"].(map[string]interface{})["type"])
+
+	tt.Equal(t, map[string]interface{}{"type": "object"}, props["next"])
+	tt.NotNil(t, props[IncludeKey])
+
+	path := filepath.Join(t.TempDir(), "conf.schema.json")
+	tt.Nil(t, WriteSchema(path, SchemaConf{}))
+	written, err := os.ReadFile(path)
+	tt.Nil(t, err)
+	tt.Equal(t, string(data), string(written))
+}
@@ -0,0 +1,301 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DecodeError a config decoding error at a position of the file,
+// Line and Column are 0 when the position is unknown
+type DecodeError struct {
+	File   string
+	Line   int
+	Column int
+	Key    string
+	Msg    string
+}
+
+func (e *DecodeError) Error() string {
+	pos := e.File
+	if e.Line > 0 {
+		if pos != "" {
+			pos += ":"
+		}
+		pos += strconv.Itoa(e.Line) + ":" + strconv.Itoa(e.Column)
+	}
+	if pos == "" {
+		pos = "conf"
+	}
+
+	if e.Key == "" {
+		return pos + ": " + e.Msg
+	}
+	return pos + ": " + e.Key + ": " + e.Msg
+}
+
+// DecodeErrors all the decoding errors of a config, sorted by position
+type DecodeErrors []*DecodeError
+
+func (e DecodeErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// sort sorts the errors by file and position
+func (e DecodeErrors) sort() DecodeErrors {
+	sort.SliceStable(e, func(i, j int) bool {
+		a, b := e[i], e[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return e
+}
+
+// position the line and column of a toml key
+type position struct {
+	line, col int
+}
+
+// tomlKeys returns the positions of the key paths in the toml data,
+// and the key path defined at each line. Array tables are not indexed,
+// their keys point at the first definition.
+func tomlKeys(data []byte) (map[string]position, map[int]string) {
+	keys := map[string]position{}
+	lines := map[int]string{}
+	table := ""
+	depth := 0
+	multi := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if multi != "" {
+			if strings.Contains(text, multi) {
+				multi = ""
+			}
+			continue
+		}
+
+		trim := strings.TrimSpace(text)
+		col := len(text) - len(strings.TrimLeft(text, " \t")) + 1
+		if trim == "" || trim[0] == '#' {
+			continue
+		}
+		if depth > 0 {
+			depth += brackets(trim)
+			continue
+		}
+
+		if trim[0] == '[' {
+			name := strings.Trim(strings.SplitN(trim, "#", 2)[0], " \t")
+			name = strings.Trim(name, "[]")
+			table = joinParts(splitKey(name))
+			if _, ok := keys[table]; !ok {
+				keys[table] = position{line, col}
+			}
+			lines[line] = table
+			continue
+		}
+
+		i := assignIndex(trim)
+		if i < 0 {
+			continue
+		}
+		key := joinKey(table, joinParts(splitKey(trim[:i])))
+		if _, ok := keys[key]; !ok {
+			keys[key] = position{line, col}
+		}
+		lines[line] = key
+
+		value := strings.TrimSpace(trim[i+1:])
+		for _, q := range []string{`"""`, "'''"} {
+			if strings.HasPrefix(value, q) && !strings.Contains(value[3:], q) {
+				multi = q
+			}
+		}
+		depth += brackets(value)
+	}
+
+	return keys, lines
+}
+
+// brackets returns the open minus the closed array and inline table
+// brackets outside of strings and comments
+func brackets(s string) int {
+	n := 0
+	quote := byte(0)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return n
+		case c == '[' || c == '{':
+			n++
+		case c == ']' || c == '}':
+			n--
+		}
+	}
+	return n
+}
+
+// assignIndex returns the index of the = after the key of the toml
+// key/value line s, skipping the quoted key parts, -1 if there is none
+func assignIndex(s string) int {
+	quote := byte(0)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '=':
+			return i
+		case c == '#':
+			return -1
+		}
+	}
+	return -1
+}
+
+// splitKey splits the dotted toml key, unquoting the parts
+func splitKey(key string) []string {
+	var parts []string
+	var part strings.Builder
+	quote := byte(0)
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			part.WriteByte(c)
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			parts = append(parts, part.String())
+			part.Reset()
+		case c != ' ' && c != '\t':
+			part.WriteByte(c)
+		}
+	}
+	return append(parts, part.String())
+}
+
+func joinParts(parts []string) string {
+	return strings.Join(parts, ".")
+}
+
+// positionOf returns the position of the key in the toml data,
+// or of its closest defined parent table
+func positionOf(keys map[string]position, key string) position {
+	for key != "" {
+		if pos, ok := keys[key]; ok {
+			return pos
+		}
+
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return position{}
+}
+
+// unknownKeys returns the key paths of m without a field in the type t
+func unknownKeys(m map[string]interface{}, t reflect.Type, path string) []string {
+	t = indirect(t)
+	switch t.Kind() {
+	case reflect.Map:
+		var keys []string
+		for k, v := range m {
+			if sub, ok := v.(map[string]interface{}); ok {
+				keys = append(keys, unknownKeys(sub, t.Elem(), joinKey(path, k))...)
+			}
+		}
+		return keys
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	fields := fieldsOf(t)
+	var keys []string
+	for k, v := range m {
+		var f *field
+		for i := range fields {
+			if strings.EqualFold(fields[i].key, k) {
+				f = &fields[i]
+				break
+			}
+		}
+
+		key := joinKey(path, k)
+		if f == nil {
+			keys = append(keys, key)
+			continue
+		}
+
+		ft := indirect(f.Type)
+		switch sub := v.(type) {
+		case map[string]interface{}:
+			keys = append(keys, unknownKeys(sub, ft, key)...)
+		case []interface{}:
+			if ft.Kind() != reflect.Slice && ft.Kind() != reflect.Array {
+				continue
+			}
+			for i, item := range sub {
+				if table, ok := item.(map[string]interface{}); ok {
+					keys = append(keys, unknownKeys(table, ft.Elem(),
+						key+"["+strconv.Itoa(i)+"]")...)
+				}
+			}
+		case []map[string]interface{}:
+			if ft.Kind() != reflect.Slice && ft.Kind() != reflect.Array {
+				continue
+			}
+			for i, table := range sub {
+				keys = append(keys, unknownKeys(table, ft.Elem(),
+					key+"["+strconv.Itoa(i)+"]")...)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
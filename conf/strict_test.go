@@ -0,0 +1,78 @@
+package conf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+func TestStrict(t *testing.T) {
+	app := App{}
+	err := Load("../testdata/strict.toml", &app)
+	tt.Nil(t, err)
+
+	err = Load("../testdata/strict.toml", &app, WithStrict())
+	var errs DecodeErrors
+	tt.True(t, errors.As(err, &errs))
+	tt.Equal(t, 2, len(errs))
+
+	tt.Equal(t, "../testdata/strict.toml", errs[0].File)
+	tt.Equal(t, 6, errs[0].Line)
+	tt.Equal(t, 1, errs[0].Column)
+	tt.Equal(t, "server.prot", errs[0].Key)
+	tt.Equal(t, "../testdata/strict.toml:6:1: server.prot: unknown key", errs[0].Error())
+	tt.Equal(t, "db", errs[1].Key)
+	tt.Equal(t, 8, errs[1].Line)
+}
+
+func TestStrictType(t *testing.T) {
+	app := App{}
+	err := Load("test = \"conf\"\n\n[server]\n  port = \"80\"\n", &app, WithEmbed())
+
+	var de *DecodeError
+	tt.True(t, errors.As(err, &de))
+	tt.Equal(t, 4, de.Line)
+	tt.True(t, de.Column >= 3)
+	tt.Equal(t, "server.port", de.Key)
+
+	err = Init("a = ", &app, true)
+	tt.True(t, errors.As(err, &de))
+	tt.Equal(t, 1, de.Line)
+}
+
+func TestStrictFormat(t *testing.T) {
+	app := App{}
+	err := Load("test: conf\nserver:\n  prot: 80\n", &app,
+		WithEmbed(), WithFormat(YAML), WithStrict())
+	tt.Equal(t, "conf: server.prot: unknown key", err.Error())
+
+	_, err = LoadLayers(&app, []Layer{{Path: "../testdata/server.toml"},
+		{Name: "local", Content: "[server]\nprot = 80"}}, WithStrict())
+	tt.Equal(t, "local: server.prot: unknown key", err.Error())
+}
+
+func TestTomlKeys(t *testing.T) {
+	keys, lines := tomlKeys([]byte(`a = 1
+b = """
+c = 2
+"""
+d = [
+  "e = 3",
+]
+[t."x.y"]
+  f.g = { h = 1 }
+`))
+	tt.Equal(t, position{1, 1}, keys["a"])
+	tt.Equal(t, position{5, 1}, keys["d"])
+	tt.Equal(t, position{9, 3}, keys["t.x.y.f.g"])
+	tt.Equal(t, "t.x.y", lines[8])
+	tt.Equal(t, 5, len(keys))
+
+	keys, _ = tomlKeys([]byte(`"a=b" = 1` + "\n" + `'c' = "="`))
+	tt.Equal(t, position{1, 1}, keys["a=b"])
+	tt.Equal(t, position{2, 1}, keys["c"])
+	tt.Equal(t, 2, len(keys))
+	tt.Equal(t, 6, assignIndex(`"a=b" = 1`))
+	tt.Equal(t, -1, assignIndex(`# a = 1`))
+}
@@ -14,25 +14,72 @@
 package conf
 
 import (
-	"log"
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
-// Init toml file config
-func Init(filePath string, config interface{}, embed1 ...bool) (err error) {
-	confLock.Lock()
-	if len(embed1) > 0 {
-		_, err = toml.Decode(filePath, config)
-	} else {
-		_, err = toml.DecodeFile(filePath, config)
-	}
+// lineErr the BurntSushi/toml decoding error of a value
+var lineErr = regexp.MustCompile(`^toml: line (\d+) \(last key "([^"]*)"\): (.*)$`)
+
+// unmarshal decodes the toml data into config
+func unmarshal(data []byte, config interface{}) error {
+	_, err := toml.Decode(string(data), config)
+	return err
+}
+
+// marshal encodes the config into toml
+func marshal(config interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := toml.NewEncoder(&buf).Encode(config)
+	return buf.Bytes(), err
+}
 
+// decodeFile decodes the toml data of the file into config, returning
+// the errors as *DecodeError or DecodeErrors with their position.
+// With strict the unknown keys are errors.
+func decodeFile(name string, data []byte, config interface{}, strict bool) error {
+	md, err := toml.Decode(string(data), config)
 	if err != nil {
-		log.Println("toml.DecodeFile error: ", err)
-		return err
+		var pe toml.ParseError
+		if errors.As(err, &pe) {
+			return &DecodeError{File: name, Line: pe.Position.Line,
+				Column: pe.Position.Col, Key: pe.LastKey, Msg: pe.Message}
+		}
+
+		m := lineErr.FindStringSubmatch(err.Error())
+		if m == nil {
+			return err
+		}
+		line, _ := strconv.Atoi(m[1])
+		keys, _ := tomlKeys(data)
+		return &DecodeError{File: name, Line: line,
+			Column: positionOf(keys, m[2]).col, Key: m[2], Msg: m[3]}
+	}
+
+	undecoded := md.Undecoded()
+	if !strict || len(undecoded) == 0 {
+		return nil
 	}
-	confLock.Unlock()
 
-	return nil
+	skip := map[string]bool{}
+	keys, _ := tomlKeys(data)
+	errs := DecodeErrors{}
+	for _, k := range undecoded {
+		key := k.String()
+		skip[key] = true
+		// report an unknown table, not each of its keys
+		if len(k) > 1 && skip[strings.Join(k[:len(k)-1], ".")] {
+			continue
+		}
+
+		pos := positionOf(keys, key)
+		errs = append(errs, &DecodeError{File: name, Line: pos.line,
+			Column: pos.col, Key: key, Msg: "unknown key"})
+	}
+	return errs.sort()
 }
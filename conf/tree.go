@@ -0,0 +1,183 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// ErrNotFound the key path is not set
+var ErrNotFound = errors.New("not found")
+
+// Tree an untyped config accessed by key paths, e.g. server.tls.cert
+// or server.hosts[0], for configs without a struct known at compile time
+type Tree struct {
+	m map[string]interface{}
+}
+
+// NewTree returns the tree of the table m
+func NewTree(m map[string]interface{}) *Tree {
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+	return &Tree{m: m}
+}
+
+// LoadTree load the config file into a tree, the format is picked
+// and the included files are merged like Load. Without a struct to
+// nest them by, the dotenv keys stay flat, e.g. SERVER_PORT.
+func LoadTree(path string, opts ...Option) (*Tree, error) {
+	o := newOptions(opts)
+	l := Layer{Path: path, Format: o.formatOf(path), FS: o.fsys}
+	if o.embed {
+		l = Layer{Name: "embed", Content: path, Format: o.formatOf(path), FS: o.fsys}
+	}
+
+	m, _, err := mergeLayers([]Layer{l}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewTree(m), nil
+}
+
+// LoadTreeLayers load the deep-merged config layers into a tree,
+// see LoadLayers and LoadTree
+func LoadTreeLayers(layers []Layer) (*Tree, Origin, error) {
+	m, origin, err := mergeLayers(layers, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewTree(m), origin, nil
+}
+
+// Map returns the table of the tree
+func (t *Tree) Map() map[string]interface{} {
+	return t.m
+}
+
+// Keys returns the sorted top-level keys of the tree
+func (t *Tree) Keys() []string {
+	keys := make([]string, 0, len(t.m))
+	for k := range t.m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lookup returns the value at the key path
+func (t *Tree) lookup(key string) (interface{}, bool) {
+	v, ok := valueAt(reflect.ValueOf(t.m), key)
+	if !ok {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// IsSet reports whether the key path is set
+func (t *Tree) IsSet(key string) bool {
+	_, ok := t.lookup(key)
+	return ok
+}
+
+// Get returns the value at the key path, nil if it is not set
+func (t *Tree) Get(key string) interface{} {
+	v, _ := t.lookup(key)
+	return v
+}
+
+// Decode decodes the value at the key path into v, a pointer,
+// coercing the types like Load; the empty key decodes the whole tree
+func (t *Tree) Decode(key string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("conf: decode into non-pointer %T", v)
+	}
+
+	var src interface{} = t.m
+	if key != "" {
+		var ok bool
+		if src, ok = t.lookup(key); !ok {
+			return fmt.Errorf("conf: %s: %w", key, ErrNotFound)
+		}
+	}
+	return assign(rv.Elem(), src, key)
+}
+
+// GetString returns the string at the key path, scalars are formatted
+func (t *Tree) GetString(key string) (string, error) {
+	var s string
+	err := t.Decode(key, &s)
+	return s, err
+}
+
+// GetInt returns the int at the key path, numeric strings are parsed
+func (t *Tree) GetInt(key string) (int, error) {
+	var n int
+	err := t.Decode(key, &n)
+	return n, err
+}
+
+// GetInt64 returns the int64 at the key path, numeric strings are parsed
+func (t *Tree) GetInt64(key string) (int64, error) {
+	var n int64
+	err := t.Decode(key, &n)
+	return n, err
+}
+
+// GetFloat returns the float64 at the key path, numeric strings are parsed
+func (t *Tree) GetFloat(key string) (float64, error) {
+	var f float64
+	err := t.Decode(key, &f)
+	return f, err
+}
+
+// GetBool returns the bool at the key path, strings are parsed
+// by strconv.ParseBool
+func (t *Tree) GetBool(key string) (bool, error) {
+	var b bool
+	err := t.Decode(key, &b)
+	return b, err
+}
+
+// GetDuration returns the duration at the key path, strings are parsed
+// by time.ParseDuration and integers are nanoseconds
+func (t *Tree) GetDuration(key string) (time.Duration, error) {
+	var d time.Duration
+	err := t.Decode(key, &d)
+	return d, err
+}
+
+// GetStringSlice returns the strings at the key path, a string is
+// split on commas
+func (t *Tree) GetStringSlice(key string) ([]string, error) {
+	var s []string
+	err := t.Decode(key, &s)
+	return s, err
+}
+
+// Sub returns the tree of the table at the key path
+func (t *Tree) Sub(key string) (*Tree, error) {
+	v, ok := t.lookup(key)
+	if !ok {
+		return nil, fmt.Errorf("conf: %s: %w", key, ErrNotFound)
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("conf: %s: %T is not a table", key, v)
+	}
+	return NewTree(m), nil
+}
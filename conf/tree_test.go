@@ -0,0 +1,103 @@
+package conf
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vcaesar/tt"
+)
+
+const treeConf = `
+name = "gt"
+debug = "true"
+
+[server]
+port = "8080"
+timeout = "5s"
+hosts = "a, b"
+
+[server.tls]
+cert = "cert.pem"
+
+[[plugins]]
+name = "auth"
+`
+
+func TestTree(t *testing.T) {
+	tree, err := LoadTree(treeConf, WithEmbed())
+	tt.Nil(t, err)
+	tt.Equal(t, []string{"debug", "name", "plugins", "server"}, tree.Keys())
+
+	tt.Equal(t, "gt", tree.Get("name"))
+	tt.Nil(t, tree.Get("none"))
+	tt.True(t, tree.IsSet("server.tls.cert"))
+	tt.False(t, tree.IsSet("server.tls.key"))
+
+	port, err := tree.GetInt("server.port")
+	tt.Nil(t, err)
+	tt.Equal(t, 8080, port)
+
+	debug, err := tree.GetBool("debug")
+	tt.Nil(t, err)
+	tt.True(t, debug)
+
+	d, err := tree.GetDuration("server.timeout")
+	tt.Nil(t, err)
+	tt.Equal(t, 5*time.Second, d)
+
+	hosts, err := tree.GetStringSlice("server.hosts")
+	tt.Nil(t, err)
+	tt.Equal(t, []string{"a", "b"}, hosts)
+
+	name, err := tree.GetString("plugins[0].name")
+	tt.Nil(t, err)
+	tt.Equal(t, "auth", name)
+
+	sub, err := tree.Sub("server")
+	tt.Nil(t, err)
+	cert, err := sub.GetString("tls.cert")
+	tt.Nil(t, err)
+	tt.Equal(t, "cert.pem", cert)
+
+	_, err = tree.GetInt("name")
+	tt.NotNil(t, err)
+	_, err = tree.GetInt("server.none")
+	tt.True(t, errors.Is(err, ErrNotFound))
+	_, err = tree.Sub("name")
+	tt.NotNil(t, err)
+
+	app := App{}
+	tt.Nil(t, NewTree(map[string]interface{}{"test": "conf"}).Decode("", &app))
+	tt.Equal(t, "conf", app.Test)
+}
+
+func TestTreeLayers(t *testing.T) {
+	tree, origin, err := LoadTreeLayers([]Layer{{Path: "../testdata/conf.yaml"},
+		{Name: "local", Content: "[server]\nport = 9000"}})
+	tt.Nil(t, err)
+
+	port, err := tree.GetInt("server.port")
+	tt.Nil(t, err)
+	tt.Equal(t, 9000, port)
+	tt.Equal(t, "local", origin.Of("server.port"))
+	tt.Equal(t, "localhost", tree.Get("server.host"))
+}
+
+func TestTreeInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"conf.toml": "include = \"db.toml\"\ntest = \"root\"",
+		"db.toml":   "[db]\nport = 5432",
+	})
+
+	tree, err := LoadTree(filepath.Join(dir, "conf.toml"))
+	tt.Nil(t, err)
+	tt.Equal(t, "root", tree.Get("test"))
+
+	port, err := tree.GetInt("db.port")
+	tt.Nil(t, err)
+	tt.Equal(t, 5432, port)
+	tt.Nil(t, tree.Get("include"))
+}
@@ -0,0 +1,194 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ValidationError a config field violating a `validate` rule
+type ValidationError struct {
+	Key  string
+	Rule string
+	Msg  string
+}
+
+func (e *ValidationError) Error() string {
+	return "conf: " + e.Key + ": " + e.Msg
+}
+
+// ValidationErrors all the `validate` rule violations of a config
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Defaults fill the zero-valued config fields from the `default:"..."` tag,
+// Load runs it with WithDefaults
+func Defaults(config interface{}) error {
+	rv := reflect.ValueOf(config)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("conf: defaults into non-pointer %T", config)
+	}
+	if indirect(rv.Type()).Kind() != reflect.Struct {
+		return fmt.Errorf("conf: defaults into non-struct %T", config)
+	}
+
+	v := rv.Elem()
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return defaults(v, "")
+}
+
+func defaults(v reflect.Value, path string) error {
+	for _, f := range fieldsOf(v.Type()) {
+		key := joinKey(path, f.key)
+		fv := v.FieldByIndex(f.Index)
+
+		def, ok := f.Tag.Lookup("default")
+		if ok && fv.IsZero() {
+			if err := assign(fv, def, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if isTable(f.Type) && !(fv.Kind() == reflect.Ptr && fv.IsNil()) {
+			if err := defaults(reflect.Indirect(fv), key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate check the config fields by the `validate` tag rules,
+// separated by commas:
+//
+//	required     the field is not zero
+//	min=1        the number, or the length, is at least 1
+//	max=65535    the number, or the length, is at most 65535
+//	oneof=a|b    the value is one of a or b
+//
+// All the violations are returned as ValidationErrors.
+func Validate(config interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(config))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("conf: validate non-struct %T", config)
+	}
+
+	var errs ValidationErrors
+	validate(rv, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validate(v reflect.Value, path string, errs *ValidationErrors) {
+	for _, f := range fieldsOf(v.Type()) {
+		key := joinKey(path, f.key)
+		fv := v.FieldByIndex(f.Index)
+
+		for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+			if rule = strings.TrimSpace(rule); rule == "" {
+				continue
+			}
+			if msg := check(fv, rule); msg != "" {
+				*errs = append(*errs, &ValidationError{Key: key, Rule: rule, Msg: msg})
+			}
+		}
+
+		ev := reflect.Indirect(fv)
+		switch {
+		case !ev.IsValid():
+		case isTable(ev.Type()):
+			validate(ev, key, errs)
+		case ev.Kind() == reflect.Slice && isTable(ev.Type().Elem()):
+			for i := 0; i < ev.Len(); i++ {
+				if item := reflect.Indirect(ev.Index(i)); item.IsValid() {
+					validate(item, key+"["+strconv.Itoa(i)+"]", errs)
+				}
+			}
+		}
+	}
+}
+
+// check returns the violation message of the rule, or "" if v passes
+func check(v reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	if name == "required" {
+		if v.IsZero() {
+			return "is required"
+		}
+		return ""
+	}
+
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		return ""
+	}
+
+	switch name {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return fmt.Sprintf("bad rule %q", rule)
+		}
+
+		n, what := size(v)
+		if name == "min" && n < limit {
+			return fmt.Sprintf("%s %v is less than %s", what, n, arg)
+		}
+		if name == "max" && n > limit {
+			return fmt.Sprintf("%s %v is greater than %s", what, n, arg)
+		}
+	case "oneof":
+		val := fmt.Sprint(v.Interface())
+		for _, opt := range strings.Split(arg, "|") {
+			if val == opt {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%q is not one of %s", val, strings.ReplaceAll(arg, "|", ", "))
+	default:
+		return fmt.Sprintf("unknown rule %q", rule)
+	}
+	return ""
+}
+
+// size returns the number checked by min and max, the value of
+// numbers and the length of others
+func size(v reflect.Value) (float64, string) {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), "length"
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "value"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), "value"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "value"
+	}
+	return 0, "value"
+}
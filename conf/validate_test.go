@@ -0,0 +1,71 @@
+package conf
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vcaesar/tt"
+)
+
+type Limits struct {
+	Name    string        `toml:"name" validate:"required"`
+	Port    int           `toml:"port" default:"8080" validate:"min=1,max=65535"`
+	Mode    string        `toml:"mode" default:"dev" validate:"oneof=dev|prod"`
+	Hosts   []string      `toml:"hosts" default:"a,b" validate:"min=1"`
+	Timeout time.Duration `toml:"timeout" default:"3s"`
+
+	DB struct {
+		User string `toml:"user" validate:"required"`
+	} `toml:"db"`
+}
+
+func TestDefaults(t *testing.T) {
+	l := Limits{Port: 80}
+	err := Defaults(&l)
+	tt.Nil(t, err)
+
+	tt.Equal(t, 80, l.Port)
+	tt.Equal(t, "dev", l.Mode)
+	tt.Equal(t, []string{"a", "b"}, l.Hosts)
+	tt.Equal(t, 3*time.Second, l.Timeout)
+
+	var pl *Limits
+	tt.Nil(t, Defaults(&pl))
+	tt.Equal(t, "dev", pl.Mode)
+
+	m := map[string]interface{}{}
+	tt.Equal(t, "conf: defaults into non-struct *map[string]interface {}", Defaults(&m).Error())
+	n := 1
+	pn := &n
+	tt.NotNil(t, Defaults(&pn))
+}
+
+func TestValidate(t *testing.T) {
+	l := Limits{Port: 70000, Mode: "test"}
+	err := Validate(&l)
+
+	var errs ValidationErrors
+	tt.True(t, errors.As(err, &errs))
+	tt.Equal(t, 5, len(errs))
+	tt.Equal(t, "name", errs[0].Key)
+	tt.Equal(t, "port", errs[1].Key)
+	tt.Equal(t, "max=65535", errs[1].Rule)
+	tt.Equal(t, "mode", errs[2].Key)
+	tt.Equal(t, "hosts", errs[3].Key)
+	tt.Equal(t, "db.user", errs[4].Key)
+	tt.Equal(t, `conf: mode: "test" is not one of dev, prod`, errs[2].Error())
+}
+
+func TestLoadValidate(t *testing.T) {
+	l := Limits{}
+	err := Load("name = \"gt\"\nport = 9000\n[db]\nuser = \"root\"", &l,
+		WithEmbed(), WithDefaults(), WithValidate())
+	tt.Nil(t, err)
+	tt.Equal(t, 9000, l.Port)
+	tt.Equal(t, "dev", l.Mode)
+
+	l = Limits{}
+	err = Load("name = \"gt\"", &l, WithEmbed(), WithDefaults(), WithValidate())
+	tt.Equal(t, "conf: db.user: is required", err.Error())
+}
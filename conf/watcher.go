@@ -0,0 +1,175 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package conf
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Watcher holds the config decoded from a watched file, every
+// successful reload is decoded into a new *T and swapped in atomically,
+// so the snapshot returned by Get must not be modified.
+type Watcher[T any] struct {
+	path string
+	opts []Option
+	load func(cfg *T) error
+
+	cur atomic.Pointer[T]
+
+	mu        sync.Mutex
+	callbacks []func(old, new *T)
+	diffs     []func(changes []Change)
+
+	errs   chan error
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// WatchFile load the config file at path and reload it on every change,
+// until Close is called or ctx is done. The options are used on each load.
+//
+// The directories of the file and of its included files are watched, so
+// atomic saves, renames and symlink swaps are followed. A burst of events
+// is debounced into one reload, and a reload that fails keeps the last
+// good config.
+func WatchFile[T any](ctx context.Context, path string, opts ...Option) (*Watcher[T], error) {
+	w := &Watcher[T]{
+		path: path,
+		opts: opts,
+		errs: make(chan error, 16),
+		done: make(chan struct{}),
+	}
+	w.load = func(cfg *T) error {
+		return Load(path, cfg, opts...)
+	}
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	fw, err := newFileWatch(newOptions(opts).debounce, w.files()...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(w.done)
+		fw.run(ctx, func() []string {
+			if err := w.Reload(); err != nil {
+				w.report(err)
+			}
+			return w.files()
+		}, w.report)
+	}()
+	return w, nil
+}
+
+// Get returns the current config snapshot
+func (w *Watcher[T]) Get() *T {
+	return w.cur.Load()
+}
+
+// OnChange register the callback called with the old and new config
+// after each successful reload
+func (w *Watcher[T]) OnChange(fn func(old, new *T)) {
+	w.mu.Lock()
+	w.callbacks = append(w.callbacks, fn)
+	w.mu.Unlock()
+}
+
+// OnDiff register the callback called with the changes of each
+// successful reload that changed a value, see Diff
+func (w *Watcher[T]) OnDiff(fn func(changes []Change)) {
+	w.mu.Lock()
+	w.diffs = append(w.diffs, fn)
+	w.mu.Unlock()
+}
+
+// OnPathChange register the callback called with each change of the
+// value at the key path, or under the table at the key path
+func (w *Watcher[T]) OnPathChange(path string, fn func(c Change)) {
+	w.OnDiff(func(changes []Change) {
+		for _, c := range changes {
+			if under(c.Path, path) {
+				fn(c)
+			}
+		}
+	})
+}
+
+// Errors returns the channel of the watch and reload errors, errors are
+// dropped while the channel is full
+func (w *Watcher[T]) Errors() <-chan error {
+	return w.errs
+}
+
+// Done returns a channel closed when the watcher stops
+func (w *Watcher[T]) Done() <-chan struct{} {
+	return w.done
+}
+
+// Close stop watching the config
+func (w *Watcher[T]) Close() error {
+	w.cancel()
+	<-w.done
+	return nil
+}
+
+// Reload load the config, keeping the current config on errors
+func (w *Watcher[T]) Reload() error {
+	cfg := new(T)
+	if err := w.load(cfg); err != nil {
+		return err
+	}
+	w.swap(cfg)
+	return nil
+}
+
+// files returns the config file and its included files
+func (w *Watcher[T]) files() []string {
+	return configFiles(w.path, newOptions(w.opts).formatOf(w.path))
+}
+
+// swap store the new config and call the callbacks
+func (w *Watcher[T]) swap(cfg *T) {
+	old := w.cur.Swap(cfg)
+	if old == nil {
+		return
+	}
+
+	w.mu.Lock()
+	callbacks := append([]func(old, new *T){}, w.callbacks...)
+	diffs := append([]func(changes []Change){}, w.diffs...)
+	w.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(old, cfg)
+	}
+
+	if len(diffs) == 0 {
+		return
+	}
+	if changes := Diff(old, cfg); len(changes) > 0 {
+		for _, fn := range diffs {
+			fn(changes)
+		}
+	}
+}
+
+// report send the error without blocking the watcher
+func (w *Watcher[T]) report(err error) {
+	select {
+	case w.errs <- err:
+	default:
+	}
+}
@@ -0,0 +1,133 @@
+package conf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vcaesar/tt"
+)
+
+// wait returns the value received from ch, failing after a timeout
+func wait[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+	var zero T
+	return zero
+}
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf.toml")
+	tt.Nil(t, os.WriteFile(path, []byte(`test = "v1"`), 0644))
+
+	w, err := WatchFile[Toml](context.Background(), path)
+	tt.Nil(t, err)
+	defer w.Close()
+	tt.Equal(t, "v1", w.Get().Test)
+
+	changes := make(chan string, 16)
+	w.OnChange(func(old, new *Toml) {
+		changes <- new.Test
+	})
+
+	tt.Nil(t, os.WriteFile(path, []byte(`test = "v2"`), 0644))
+	tt.Equal(t, "v2", wait(t, changes))
+	tt.Equal(t, "v2", w.Get().Test)
+
+	tt.Nil(t, w.Close())
+	tt.Nil(t, os.WriteFile(path, []byte(`test = `), 0644))
+	tt.NotNil(t, w.Reload())
+	tt.Equal(t, "v2", w.Get().Test)
+}
+
+func TestWatchFileCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w, err := WatchFile[Toml](ctx, "../testdata/conf.toml")
+	tt.Nil(t, err)
+	tt.Equal(t, "conf", w.Get().Test)
+
+	cancel()
+	wait(t, w.Done())
+
+	_, err = WatchFile[Toml](ctx, "../testdata/none.toml")
+	tt.NotNil(t, err)
+}
+
+func TestWatchFileAtomicSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "conf.toml")
+	tt.Nil(t, os.WriteFile(path, []byte(`test = "v1"`), 0644))
+
+	w, err := WatchFile[Toml](context.Background(), path, WithDebounce(20*time.Millisecond))
+	tt.Nil(t, err)
+	defer w.Close()
+
+	changes := make(chan string, 16)
+	w.OnChange(func(old, new *Toml) {
+		changes <- new.Test
+	})
+
+	for _, v := range []string{"v2", "v3"} {
+		tmp := filepath.Join(dir, "conf.toml.tmp")
+		tt.Nil(t, os.WriteFile(tmp, []byte(`test = "`+v+`"`), 0644))
+		tt.Nil(t, os.Rename(tmp, path))
+		tt.Equal(t, v, wait(t, changes))
+	}
+
+	tt.Nil(t, os.WriteFile(path, []byte(`test = `), 0644))
+	tt.NotNil(t, wait(t, w.Errors()))
+	tt.Equal(t, "v3", w.Get().Test)
+}
+
+func TestWatchFileSymlink(t *testing.T) {
+	// the Kubernetes ConfigMap layout: conf.toml -> ..data/conf.toml,
+	// ..data -> ..v1 swapped by renaming a new link over it
+	dir := t.TempDir()
+	write := func(version string) {
+		tt.Nil(t, os.Mkdir(filepath.Join(dir, version), 0755))
+		tt.Nil(t, os.WriteFile(filepath.Join(dir, version, "conf.toml"),
+			[]byte(`test = "`+version+`"`), 0644))
+		tt.Nil(t, os.Symlink(version, filepath.Join(dir, "..tmp")))
+		tt.Nil(t, os.Rename(filepath.Join(dir, "..tmp"), filepath.Join(dir, "..data")))
+	}
+	write("..v1")
+	path := filepath.Join(dir, "conf.toml")
+	tt.Nil(t, os.Symlink(filepath.Join("..data", "conf.toml"), path))
+
+	w, err := WatchFile[Toml](context.Background(), path, WithDebounce(20*time.Millisecond))
+	tt.Nil(t, err)
+	defer w.Close()
+	tt.Equal(t, "..v1", w.Get().Test)
+
+	changes := make(chan string, 16)
+	w.OnChange(func(old, new *Toml) {
+		changes <- new.Test
+	})
+
+	write("..v2")
+	tt.Nil(t, os.RemoveAll(filepath.Join(dir, "..v1")))
+	tt.Equal(t, "..v2", wait(t, changes))
+
+	write("..v3")
+	tt.Equal(t, "..v3", wait(t, changes))
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf.toml")
+	tt.Nil(t, os.WriteFile(path, []byte(`test = "v1"`), 0644))
+
+	toml := Toml{}
+	tt.Nil(t, reload(path, &toml))
+	tt.Equal(t, "v1", toml.Test)
+
+	tt.Nil(t, os.WriteFile(path, []byte("test = \"v2\"\nbad"), 0644))
+	tt.NotNil(t, reload(path, &toml))
+	tt.Equal(t, "v1", toml.Test)
+}
@@ -0,0 +1,298 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package hset
+
+import "unsafe"
+
+// lockPair locks the hset, for writing if write is true, and read locks
+// other. The locks are taken in address order, so two goroutines
+// combining the same pair of hsets in opposite order never deadlock.
+// It returns the unlock func; a nil other is an empty hset.
+func (hset *Of[T]) lockPair(other *Of[T], write bool) func() {
+	lock := func(set *Of[T]) func() {
+		if write && set == hset {
+			set.Lock()
+			return set.Unlock
+		}
+		set.RLock()
+		return set.RUnlock
+	}
+
+	if other == nil || other == hset {
+		return lock(hset)
+	}
+
+	first, second := hset, other
+	if uintptr(unsafe.Pointer(other)) < uintptr(unsafe.Pointer(hset)) {
+		first, second = other, hset
+	}
+	unlock1 := lock(first)
+	unlock2 := lock(second)
+	return func() {
+		unlock2()
+		unlock1()
+	}
+}
+
+// itemsOf returns the items of the hset, nil if it is nil
+func itemsOf[T comparable](hset *Of[T]) map[T]struct{} {
+	if hset == nil {
+		return nil
+	}
+	return hset.items
+}
+
+// combine returns the items computed from the items of the two
+// hsets, holding their read locks
+func (hset *Of[T]) combine(other *Of[T], fn func(a, b map[T]struct{}) map[T]struct{}) map[T]struct{} {
+	unlock := hset.lockPair(other, false)
+	defer unlock()
+	return fn(hset.items, itemsOf(other))
+}
+
+// check returns the predicate on the items of the two hsets,
+// holding their read locks
+func (hset *Of[T]) check(other *Of[T], fn func(a, b map[T]struct{}) bool) bool {
+	unlock := hset.lockPair(other, false)
+	defer unlock()
+	return fn(hset.items, itemsOf(other))
+}
+
+// update changes the items of the hset from the items of other,
+// holding the write lock of the hset and the read lock of other
+func (hset *Of[T]) update(other *Of[T], fn func(a, b map[T]struct{})) {
+	unlock := hset.lockPair(other, true)
+	defer unlock()
+	if hset.items == nil {
+		hset.items = make(map[T]struct{})
+	}
+	fn(hset.items, itemsOf(other))
+}
+
+func union[T comparable](a, b map[T]struct{}) map[T]struct{} {
+	m := make(map[T]struct{}, len(a)+len(b))
+	for k := range a {
+		m[k] = itemExists
+	}
+	for k := range b {
+		m[k] = itemExists
+	}
+	return m
+}
+
+func intersection[T comparable](a, b map[T]struct{}) map[T]struct{} {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+
+	m := make(map[T]struct{})
+	for k := range a {
+		if _, ok := b[k]; ok {
+			m[k] = itemExists
+		}
+	}
+	return m
+}
+
+func difference[T comparable](a, b map[T]struct{}) map[T]struct{} {
+	m := make(map[T]struct{})
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			m[k] = itemExists
+		}
+	}
+	return m
+}
+
+func symmetricDifference[T comparable](a, b map[T]struct{}) map[T]struct{} {
+	m := difference(a, b)
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			m[k] = itemExists
+		}
+	}
+	return m
+}
+
+func isSubset[T comparable](a, b map[T]struct{}) bool {
+	if len(a) > len(b) {
+		return false
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isDisjoint[T comparable](a, b map[T]struct{}) bool {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	for k := range a {
+		if _, ok := b[k]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns a new hset of the items in the hset or in other.
+func (hset *Of[T]) Union(other *Of[T]) *Of[T] {
+	return &Of[T]{items: hset.combine(other, union[T])}
+}
+
+// Intersection returns a new hset of the items in both the hset and other.
+func (hset *Of[T]) Intersection(other *Of[T]) *Of[T] {
+	return &Of[T]{items: hset.combine(other, intersection[T])}
+}
+
+// Difference returns a new hset of the items in the hset but not in other.
+func (hset *Of[T]) Difference(other *Of[T]) *Of[T] {
+	return &Of[T]{items: hset.combine(other, difference[T])}
+}
+
+// SymmetricDifference returns a new hset of the items in either
+// the hset or other, but not in both.
+func (hset *Of[T]) SymmetricDifference(other *Of[T]) *Of[T] {
+	return &Of[T]{items: hset.combine(other, symmetricDifference[T])}
+}
+
+// IsSubset returns true if all the items of the hset are in other.
+func (hset *Of[T]) IsSubset(other *Of[T]) bool {
+	return hset.check(other, isSubset[T])
+}
+
+// IsSuperset returns true if all the items of other are in the hset.
+func (hset *Of[T]) IsSuperset(other *Of[T]) bool {
+	return hset.check(other, func(a, b map[T]struct{}) bool {
+		return isSubset(b, a)
+	})
+}
+
+// IsDisjoint returns true if the hset and other have no item in common.
+func (hset *Of[T]) IsDisjoint(other *Of[T]) bool {
+	return hset.check(other, isDisjoint[T])
+}
+
+// UnionWith adds the items of other to the hset.
+func (hset *Of[T]) UnionWith(other *Of[T]) {
+	hset.update(other, func(a, b map[T]struct{}) {
+		for k := range b {
+			a[k] = itemExists
+		}
+	})
+}
+
+// IntersectWith removes the items of the hset that are not in other.
+func (hset *Of[T]) IntersectWith(other *Of[T]) {
+	hset.update(other, func(a, b map[T]struct{}) {
+		for k := range a {
+			if _, ok := b[k]; !ok {
+				delete(a, k)
+			}
+		}
+	})
+}
+
+// DifferenceWith removes the items of other from the hset.
+func (hset *Of[T]) DifferenceWith(other *Of[T]) {
+	hset.update(other, func(a, b map[T]struct{}) {
+		for k := range b {
+			delete(a, k)
+		}
+	})
+}
+
+// SymmetricDifferenceWith removes the items of other that are in
+// the hset, and adds the ones that are not.
+func (hset *Of[T]) SymmetricDifferenceWith(other *Of[T]) {
+	if other == hset {
+		hset.Clear()
+		return
+	}
+
+	hset.update(other, func(a, b map[T]struct{}) {
+		for k := range b {
+			if _, ok := a[k]; ok {
+				delete(a, k)
+			} else {
+				a[k] = itemExists
+			}
+		}
+	})
+}
+
+// of returns the typed hset of the hset, nil if it is nil
+func (hset *Hset) of() *Of[interface{}] {
+	if hset == nil {
+		return nil
+	}
+	return &hset.Of
+}
+
+// Union returns a new hset of the items in the hset or in other.
+func (hset *Hset) Union(other *Hset) *Hset {
+	return &Hset{Of: Of[interface{}]{items: hset.combine(other.of(), union[interface{}])}}
+}
+
+// Intersection returns a new hset of the items in both the hset and other.
+func (hset *Hset) Intersection(other *Hset) *Hset {
+	return &Hset{Of: Of[interface{}]{items: hset.combine(other.of(), intersection[interface{}])}}
+}
+
+// Difference returns a new hset of the items in the hset but not in other.
+func (hset *Hset) Difference(other *Hset) *Hset {
+	return &Hset{Of: Of[interface{}]{items: hset.combine(other.of(), difference[interface{}])}}
+}
+
+// SymmetricDifference returns a new hset of the items in either
+// the hset or other, but not in both.
+func (hset *Hset) SymmetricDifference(other *Hset) *Hset {
+	return &Hset{Of: Of[interface{}]{items: hset.combine(other.of(), symmetricDifference[interface{}])}}
+}
+
+// IsSubset returns true if all the items of the hset are in other.
+func (hset *Hset) IsSubset(other *Hset) bool {
+	return hset.Of.IsSubset(other.of())
+}
+
+// IsSuperset returns true if all the items of other are in the hset.
+func (hset *Hset) IsSuperset(other *Hset) bool {
+	return hset.Of.IsSuperset(other.of())
+}
+
+// IsDisjoint returns true if the hset and other have no item in common.
+func (hset *Hset) IsDisjoint(other *Hset) bool {
+	return hset.Of.IsDisjoint(other.of())
+}
+
+// UnionWith adds the items of other to the hset.
+func (hset *Hset) UnionWith(other *Hset) {
+	hset.Of.UnionWith(other.of())
+}
+
+// IntersectWith removes the items of the hset that are not in other.
+func (hset *Hset) IntersectWith(other *Hset) {
+	hset.Of.IntersectWith(other.of())
+}
+
+// DifferenceWith removes the items of other from the hset.
+func (hset *Hset) DifferenceWith(other *Hset) {
+	hset.Of.DifferenceWith(other.of())
+}
+
+// SymmetricDifferenceWith removes the items of other that are in
+// the hset, and adds the ones that are not.
+func (hset *Hset) SymmetricDifferenceWith(other *Hset) {
+	hset.Of.SymmetricDifferenceWith(other.of())
+}
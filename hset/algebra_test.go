@@ -0,0 +1,99 @@
+package hset
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+// sorted returns the sorted items of the hset
+func sorted(set *Of[int]) []int {
+	values := set.Values()
+	sort.Ints(values)
+	return values
+}
+
+func TestAlgebra(t *testing.T) {
+	a, b := NewOf(1, 2, 3), NewOf(3, 4)
+
+	tt.Equal(t, []int{1, 2, 3, 4}, sorted(a.Union(b)))
+	tt.Equal(t, []int{3}, sorted(a.Intersection(b)))
+	tt.Equal(t, []int{1, 2}, sorted(a.Difference(b)))
+	tt.Equal(t, []int{1, 2, 4}, sorted(a.SymmetricDifference(b)))
+	tt.Equal(t, []int{1, 2, 3}, sorted(a.Union(nil)))
+	tt.Equal(t, []int{}, sorted(a.Intersection(nil)))
+
+	tt.True(t, NewOf(1, 2).IsSubset(a))
+	tt.False(t, b.IsSubset(a))
+	tt.True(t, a.IsSuperset(NewOf(3)))
+	tt.True(t, a.IsSuperset(nil))
+	tt.True(t, a.IsSubset(a))
+	tt.False(t, a.IsDisjoint(b))
+	tt.True(t, a.IsDisjoint(NewOf(5)))
+
+	c := NewOf(1, 2, 3)
+	c.UnionWith(b)
+	tt.Equal(t, []int{1, 2, 3, 4}, sorted(c))
+	c.IntersectWith(a)
+	tt.Equal(t, []int{1, 2, 3}, sorted(c))
+	c.DifferenceWith(NewOf(1))
+	tt.Equal(t, []int{2, 3}, sorted(c))
+	c.SymmetricDifferenceWith(b)
+	tt.Equal(t, []int{2, 4}, sorted(c))
+	c.SymmetricDifferenceWith(c)
+	tt.True(t, c.Empty())
+
+	var zero Of[int]
+	zero.UnionWith(a)
+	tt.Equal(t, []int{1, 2, 3}, sorted(&zero))
+}
+
+func TestHsetAlgebra(t *testing.T) {
+	a, b := New(), New()
+	a.Add(1, "a")
+	b.Add("a", 2.5)
+
+	tt.Equal(t, 3, a.Union(b).Len())
+	tt.True(t, a.Intersection(b).Contains("a"))
+	tt.True(t, a.Difference(b).Contains(1))
+	tt.Equal(t, 2, a.SymmetricDifference(b).Len())
+	tt.False(t, a.IsSubset(b))
+	tt.False(t, a.IsSuperset(b))
+	tt.False(t, a.IsDisjoint(b))
+
+	a.UnionWith(b)
+	tt.True(t, a.IsSuperset(b))
+	a.DifferenceWith(b)
+	tt.True(t, a.IsDisjoint(b))
+	a.SymmetricDifferenceWith(b)
+	tt.Equal(t, 3, a.Len())
+	a.IntersectWith(b)
+	tt.True(t, a.Of.Same(&b.Of))
+	a.UnionWith(nil)
+	tt.Equal(t, 2, a.Len())
+}
+
+func TestAlgebraConcurrent(t *testing.T) {
+	a, b := NewOf(1, 2, 3), NewOf(3, 4, 5)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			a.UnionWith(b)
+			a.Intersection(b)
+		}()
+		go func() {
+			defer wg.Done()
+			b.UnionWith(a)
+			b.IsSubset(a)
+		}()
+	}
+	wg.Wait()
+
+	tt.True(t, a.Same(b))
+	tt.Equal(t, []int{1, 2, 3, 4, 5}, sorted(a))
+}
@@ -0,0 +1,275 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+package hset
+
+import (
+	"fmt"
+	"iter"
+	"sort"
+	"sync"
+)
+
+// Bag a multiset holding the number of times each item was added,
+// usable as a zero value.
+type Bag struct {
+	items map[interface{}]int
+	size  int
+	sync.RWMutex
+}
+
+// BagItem an item of a bag and its count
+type BagItem struct {
+	Item  interface{}
+	Count int
+}
+
+// String returns the item and its count, e.g. "a:2"
+func (item BagItem) String() string {
+	return fmt.Sprintf("%v:%d", item.Item, item.Count)
+}
+
+// NewBag instantiates a new bag of the items
+func NewBag(items ...interface{}) *Bag {
+	bag := &Bag{items: make(map[interface{}]int)}
+	bag.Add(items...)
+	return bag
+}
+
+// add adds n times the item, the bag is locked
+func (bag *Bag) add(item interface{}, n int) {
+	if bag.items == nil {
+		bag.items = make(map[interface{}]int)
+	}
+	bag.items[item] += n
+	bag.size += n
+}
+
+// Add adds the items (one or more) to the bag once each.
+func (bag *Bag) Add(items ...interface{}) {
+	bag.Lock()
+	defer bag.Unlock()
+	for _, item := range items {
+		bag.add(item, 1)
+	}
+}
+
+// AddCount adds n times the item to the bag, n <= 0 adds nothing.
+func (bag *Bag) AddCount(item interface{}, n int) {
+	if n <= 0 {
+		return
+	}
+
+	bag.Lock()
+	defer bag.Unlock()
+	bag.add(item, n)
+}
+
+// remove removes n times the item at most and returns the removed
+// count, the bag is locked
+func (bag *Bag) remove(item interface{}, n int) int {
+	count := bag.items[item]
+	if n >= count {
+		n = count
+		delete(bag.items, item)
+	} else {
+		bag.items[item] = count - n
+	}
+	bag.size -= n
+	return n
+}
+
+// Remove removes the items (one or more) from the bag once each.
+func (bag *Bag) Remove(items ...interface{}) {
+	bag.Lock()
+	defer bag.Unlock()
+	for _, item := range items {
+		bag.remove(item, 1)
+	}
+}
+
+// RemoveCount removes n times the item from the bag, or all of them if
+// n <= 0, and returns the removed count.
+func (bag *Bag) RemoveCount(item interface{}, n int) int {
+	bag.Lock()
+	defer bag.Unlock()
+	if n <= 0 {
+		n = bag.items[item]
+	}
+	return bag.remove(item, n)
+}
+
+// Count returns the number of times the item is in the bag.
+func (bag *Bag) Count(item interface{}) int {
+	bag.RLock()
+	defer bag.RUnlock()
+	return bag.items[item]
+}
+
+// Contains check if items (one or more) are present in the bag.
+// All items have to be present in the bag for the method to return true.
+// Returns true if no arguments are passed at all.
+func (bag *Bag) Contains(items ...interface{}) bool {
+	bag.RLock()
+	defer bag.RUnlock()
+	for _, item := range items {
+		if _, ok := bag.items[item]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear removes all the items of the bag.
+func (bag *Bag) Clear() {
+	bag.Lock()
+	defer bag.Unlock()
+	bag.items = make(map[interface{}]int)
+	bag.size = 0
+}
+
+// Empty returns true if bag does not contain any elements.
+func (bag *Bag) Empty() bool {
+	return bag.Len() == 0
+}
+
+// Len returns the number of items in the bag, counting the duplicates.
+func (bag *Bag) Len() int {
+	bag.RLock()
+	defer bag.RUnlock()
+	return bag.size
+}
+
+// Unique returns the number of distinct items in the bag.
+func (bag *Bag) Unique() int {
+	bag.RLock()
+	defer bag.RUnlock()
+	return len(bag.items)
+}
+
+// Values returns the distinct items in the bag.
+func (bag *Bag) Values() []interface{} {
+	bag.RLock()
+	defer bag.RUnlock()
+	values := make([]interface{}, 0, len(bag.items))
+	for item := range bag.items {
+		values = append(values, item)
+	}
+	return values
+}
+
+// All returns an iterator over the distinct items and their counts,
+// the bag is read locked during the iteration.
+func (bag *Bag) All() iter.Seq2[interface{}, int] {
+	return func(yield func(interface{}, int) bool) {
+		bag.RLock()
+		defer bag.RUnlock()
+		for item, n := range bag.items {
+			if !yield(item, n) {
+				return
+			}
+		}
+	}
+}
+
+// counts returns a copy of the item counts
+func (bag *Bag) counts() map[interface{}]int {
+	if bag == nil {
+		return nil
+	}
+
+	bag.RLock()
+	defer bag.RUnlock()
+	counts := make(map[interface{}]int, len(bag.items))
+	for item, n := range bag.items {
+		counts[item] = n
+	}
+	return counts
+}
+
+// bagItems returns the items of the counts, by count descending, then
+// by Compare
+func bagItems(counts map[interface{}]int) []BagItem {
+	items := make([]BagItem, 0, len(counts))
+	for item, n := range counts {
+		items = append(items, BagItem{item, n})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return Compare(items[i].Item, items[j].Item) < 0
+	})
+	return items
+}
+
+// MostCommon returns the n most common items and their counts, by
+// count descending, then by Compare; all of them if n <= 0.
+func (bag *Bag) MostCommon(n int) []BagItem {
+	items := bagItems(bag.counts())
+	if n > 0 && n < len(items) {
+		items = items[:n]
+	}
+	return items
+}
+
+// Union returns a new bag with the items of both bags, counted by the
+// sum of their counts.
+func (bag *Bag) Union(other *Bag) *Bag {
+	union := &Bag{items: bag.counts()}
+	for _, n := range union.items {
+		union.size += n
+	}
+	for item, n := range other.counts() {
+		union.add(item, n)
+	}
+	return union
+}
+
+// Intersection returns a new bag with the items in both bags, counted
+// by the least of their counts.
+func (bag *Bag) Intersection(other *Bag) *Bag {
+	counts := other.counts()
+	inter := NewBag()
+	for item, n := range bag.counts() {
+		if m, ok := counts[item]; ok {
+			inter.add(item, min(n, m))
+		}
+	}
+	return inter
+}
+
+// Same to determine whether the two bags hold the same items with the
+// same counts.
+func (bag *Bag) Same(other *Bag) bool {
+	if other == nil {
+		return false
+	}
+
+	counts, others := bag.counts(), other.counts()
+	if len(counts) != len(others) {
+		return false
+	}
+	for item, n := range counts {
+		if others[item] != n {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of container, the items and
+// their counts sorted by Compare, e.g. "a:2, b:1"
+func (bag *Bag) String() string {
+	items := bagItems(bag.counts())
+	sort.SliceStable(items, func(i, j int) bool {
+		return Compare(items[i].Item, items[j].Item) < 0
+	})
+	return formatItems(items)
+}
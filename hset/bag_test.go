@@ -0,0 +1,75 @@
+package hset
+
+import (
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+func TestBag(t *testing.T) {
+	bag := NewBag("a", "b", "a")
+	bag.AddCount("c", 3)
+	bag.AddCount("d", 0)
+	tt.Equal(t, 6, bag.Len())
+	tt.Equal(t, 3, bag.Unique())
+	tt.Equal(t, 2, bag.Count("a"))
+	tt.Equal(t, 0, bag.Count("d"))
+	tt.True(t, bag.Contains("a", "c"))
+	tt.False(t, bag.Contains("d"))
+	tt.Equal(t, "a:2, b:1, c:3", bag.String())
+
+	bag.Remove("a", "d")
+	tt.Equal(t, 1, bag.Count("a"))
+	tt.Equal(t, 2, bag.RemoveCount("c", 2))
+	tt.Equal(t, 1, bag.RemoveCount("c", 5))
+	tt.Equal(t, 1, bag.RemoveCount("b", 0))
+	tt.False(t, bag.Contains("c"))
+	tt.Equal(t, 1, bag.Len())
+	tt.Equal(t, []interface{}{"a"}, bag.Values())
+
+	n := 0
+	for item, count := range bag.All() {
+		tt.Equal(t, "a", item)
+		n += count
+	}
+	tt.Equal(t, 1, n)
+
+	bag.Clear()
+	tt.True(t, bag.Empty())
+
+	var zero Bag
+	zero.Add(1)
+	tt.Equal(t, 1, zero.Count(1))
+	tt.Equal(t, 1, zero.RemoveCount(1, 0))
+}
+
+func TestBagMostCommon(t *testing.T) {
+	bag := NewBag("go", "rust", "go", "c", "rust", "go", "zig")
+	tt.Equal(t, []BagItem{{"go", 3}, {"rust", 2}}, bag.MostCommon(2))
+	tt.Equal(t, "go:3", bag.MostCommon(1)[0].String())
+	tt.Equal(t, []BagItem{{"go", 3}, {"rust", 2}, {"c", 1}, {"zig", 1}},
+		bag.MostCommon(0))
+	tt.Equal(t, 4, len(bag.MostCommon(10)))
+	tt.Equal(t, 0, len(NewBag().MostCommon(1)))
+}
+
+func TestBagAlgebra(t *testing.T) {
+	a := NewBag("x", "x", "y")
+	b := NewBag("x", "y", "y", "z")
+
+	union := a.Union(b)
+	tt.Equal(t, "x:3, y:3, z:1", union.String())
+	tt.Equal(t, 7, union.Len())
+
+	inter := a.Intersection(b)
+	tt.Equal(t, "x:1, y:1", inter.String())
+	tt.Equal(t, 2, inter.Len())
+
+	tt.Equal(t, "x:4, y:2", a.Union(a).String())
+	tt.Equal(t, 0, a.Intersection(nil).Len())
+	tt.Equal(t, 3, a.Union(nil).Len())
+
+	tt.True(t, a.Same(NewBag("y", "x", "x")))
+	tt.False(t, a.Same(NewBag("y", "x")))
+	tt.False(t, a.Same(nil))
+}
@@ -0,0 +1,205 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+package hset
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"sync"
+)
+
+const (
+	// bloomMagic starts the binary data of a BloomFilter
+	bloomMagic = "hsb1"
+	// maxHashes the most hashes of a BloomFilter
+	maxHashes = 64
+)
+
+// BloomFilter a probabilistic set of a fixed size: MayContain never
+// misses an added item, but may report an item never added at the false
+// positive rate the filter is sized for. The items can not be removed.
+// The zero value holds nothing, make the filter with NewBloom or
+// UnmarshalBinary.
+type BloomFilter struct {
+	words []uint64
+	m     uint64 // the number of bits
+	k     uint64 // the number of hashes
+	n     uint64 // the number of added items
+	sync.RWMutex
+}
+
+// NewBloom instantiates a new empty bloom filter holding n items at the
+// false positive rate p, e.g. 0.01
+func NewBloom(n uint64, p float64) *BloomFilter {
+	if n == 0 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	return newBloom(max(m, 64), min(max(k, 1), maxHashes))
+}
+
+func newBloom(m, k uint64) *BloomFilter {
+	return &BloomFilter{words: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// locations calls fn with the k bit indexes of the item hash
+func (f *BloomFilter) locations(item interface{}, fn func(i uint64) bool) {
+	// the double hashing of Kirsch and Mitzenmacher
+	h1 := filterHash(item)
+	h2 := mix(h1) | 1
+	for i := uint64(0); i < f.k; i++ {
+		hi, _ := bits.Mul64(h1+i*h2, f.m)
+		if !fn(hi) {
+			return
+		}
+	}
+}
+
+// Add adds the items (one or more) to the filter, the zero value
+// ignores them.
+func (f *BloomFilter) Add(items ...interface{}) {
+	f.Lock()
+	defer f.Unlock()
+	if f.m == 0 {
+		return
+	}
+	for _, item := range items {
+		f.locations(item, func(i uint64) bool {
+			f.words[i/64] |= 1 << (i % 64)
+			return true
+		})
+		f.n++
+	}
+}
+
+// MayContain returns false if the item was never added, true if it
+// probably was.
+func (f *BloomFilter) MayContain(item interface{}) bool {
+	f.RLock()
+	defer f.RUnlock()
+	if f.m == 0 {
+		return false
+	}
+
+	ok := true
+	f.locations(item, func(i uint64) bool {
+		ok = f.words[i/64]&(1<<(i%64)) != 0
+		return ok
+	})
+	return ok
+}
+
+// Len returns the number of added items, counting the duplicates.
+func (f *BloomFilter) Len() uint64 {
+	f.RLock()
+	defer f.RUnlock()
+	return f.n
+}
+
+// Cap returns the number of bits and hashes of the filter.
+func (f *BloomFilter) Cap() (m, k uint64) {
+	f.RLock()
+	defer f.RUnlock()
+	return f.m, f.k
+}
+
+// FalsePositiveRate returns the estimated false positive rate of the
+// filter from the share of the set bits.
+func (f *BloomFilter) FalsePositiveRate() float64 {
+	f.RLock()
+	defer f.RUnlock()
+	if f.m == 0 {
+		return 0
+	}
+
+	set := 0
+	for _, w := range f.words {
+		set += bits.OnesCount64(w)
+	}
+	return math.Pow(float64(set)/float64(f.m), float64(f.k))
+}
+
+// Clear removes all the items of the filter.
+func (f *BloomFilter) Clear() {
+	f.Lock()
+	defer f.Unlock()
+	clear(f.words)
+	f.n = 0
+}
+
+// Merge adds the items of the other filter, of the same size, to the
+// filter.
+func (f *BloomFilter) Merge(other *BloomFilter) error {
+	if other == f {
+		return nil
+	}
+
+	other.RLock()
+	m, k, n := other.m, other.k, other.n
+	words := append([]uint64(nil), other.words...)
+	other.RUnlock()
+
+	f.Lock()
+	defer f.Unlock()
+	if m != f.m || k != f.k {
+		return ErrFilterMismatch
+	}
+	for i, w := range words {
+		f.words[i] |= w
+	}
+	f.n += n
+	return nil
+}
+
+// MarshalBinary encodes the filter, see UnmarshalBinary
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	f.RLock()
+	defer f.RUnlock()
+	data := make([]byte, 0, len(bloomMagic)+24+8*len(f.words))
+	data = append(data, bloomMagic...)
+	data = binary.LittleEndian.AppendUint64(data, f.m)
+	data = binary.LittleEndian.AppendUint64(data, f.k)
+	data = binary.LittleEndian.AppendUint64(data, f.n)
+	for _, w := range f.words {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes the filter encoded by MarshalBinary
+func (f *BloomFilter) UnmarshalBinary(data []byte) error {
+	head := len(bloomMagic) + 24
+	if len(data) < head || string(data[:len(bloomMagic)]) != bloomMagic {
+		return ErrFilterData
+	}
+	m := binary.LittleEndian.Uint64(data[4:])
+	k := binary.LittleEndian.Uint64(data[12:])
+	n := binary.LittleEndian.Uint64(data[20:])
+	if m == 0 || k == 0 || k > maxHashes || k > m || m > uint64(len(data))*8 ||
+		uint64(len(data)-head) != (m+63)/64*8 {
+		return ErrFilterData
+	}
+
+	words := make([]uint64, (m+63)/64)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(data[head+8*i:])
+	}
+
+	f.Lock()
+	defer f.Unlock()
+	f.words, f.m, f.k, f.n = words, m, k, n
+	return nil
+}
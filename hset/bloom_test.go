@@ -0,0 +1,120 @@
+package hset
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+// falsePositives returns the share of the n items never added that
+// the filter may contain
+func falsePositives(n int, mayContain func(item interface{}) bool) float64 {
+	fp := 0
+	for i := 0; i < n; i++ {
+		if mayContain("absent/" + strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	return float64(fp) / float64(n)
+}
+
+func TestBloom(t *testing.T) {
+	for _, p := range []float64{0.1, 0.01, 0.001} {
+		f := NewBloom(10000, p)
+		for i := 0; i < 10000; i++ {
+			f.Add("item/" + strconv.Itoa(i))
+		}
+		tt.Equal(t, uint64(10000), f.Len())
+
+		for i := 0; i < 10000; i++ {
+			tt.True(t, f.MayContain("item/"+strconv.Itoa(i)))
+		}
+		rate := falsePositives(100000, f.MayContain)
+		tt.True(t, rate <= 1.5*p)
+		tt.True(t, f.FalsePositiveRate() <= 1.5*p)
+	}
+
+	f := NewBloom(100, 0.01)
+	f.Add(1, int64(1), 1.5, true, struct{ A int }{1})
+	tt.True(t, f.MayContain(1))
+	tt.True(t, f.MayContain(struct{ A int }{1}))
+	f.Clear()
+	tt.False(t, f.MayContain(1))
+	tt.Equal(t, uint64(0), f.Len())
+
+	f.Add(0.0)
+	tt.True(t, f.MayContain(math.Copysign(0, -1)))
+}
+
+func TestFilterHash(t *testing.T) {
+	type item struct {
+		A int
+		F float64
+		I interface{}
+	}
+
+	f := NewBloom(100, 0.01)
+	p := &item{A: 1}
+	f.Add(p, item{I: [1]float64{0}}, []string{"a", "b"}, nil)
+	p.A = 2
+	tt.True(t, f.MayContain(p))
+	tt.True(t, f.MayContain(item{F: math.Copysign(0, -1), I: [1]float64{math.Copysign(0, -1)}}))
+	tt.True(t, f.MayContain([]string{"a", "b"}))
+	tt.True(t, f.MayContain(nil))
+
+	tt.NotEqual(t, filterHash(item{A: 1}), filterHash(item{A: 2}))
+	tt.NotEqual(t, filterHash([]string{"ab"}), filterHash([]string{"a", "b"}))
+	tt.NotEqual(t, filterHash(struct{ A int }{1}), filterHash(struct{ B int }{1}))
+}
+
+func TestBloomMerge(t *testing.T) {
+	a, b := NewBloom(1000, 0.01), NewBloom(1000, 0.01)
+	a.Add("a", "b")
+	b.Add("c")
+	tt.Nil(t, a.Merge(b))
+	tt.True(t, a.MayContain("a"))
+	tt.True(t, a.MayContain("c"))
+	tt.Equal(t, uint64(3), a.Len())
+
+	tt.Equal(t, ErrFilterMismatch, a.Merge(NewBloom(10, 0.01)))
+	tt.Nil(t, a.Merge(a))
+}
+
+func TestBloomBinary(t *testing.T) {
+	f := NewBloom(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add(i)
+	}
+	data, err := f.MarshalBinary()
+	tt.Nil(t, err)
+
+	var g BloomFilter
+	tt.Nil(t, g.UnmarshalBinary(data))
+	tt.Equal(t, f.Len(), g.Len())
+	for i := 0; i < 1000; i++ {
+		tt.True(t, g.MayContain(i))
+	}
+	tt.Equal(t, falsePositives(1000, f.MayContain), falsePositives(1000, g.MayContain))
+
+	tt.Equal(t, ErrFilterData, g.UnmarshalBinary(data[:len(data)-1]))
+	tt.Equal(t, ErrFilterData, g.UnmarshalBinary([]byte("hsc1")))
+
+	// too many hashes
+	bad := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint64(bad[12:], 65)
+	tt.Equal(t, ErrFilterData, g.UnmarshalBinary(bad))
+	_, k := NewBloom(10, 1e-300).Cap()
+	tt.Equal(t, uint64(64), k)
+}
+
+func TestBloomZero(t *testing.T) {
+	var f BloomFilter
+	tt.False(t, f.MayContain(1))
+	f.Add(1)
+	tt.False(t, f.MayContain(1))
+	tt.Equal(t, uint64(0), f.Len())
+	tt.Equal(t, 0.0, f.FalsePositiveRate())
+}
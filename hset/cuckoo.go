@@ -0,0 +1,358 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+package hset
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+const (
+	// cuckooMagic starts the binary data of a CuckooFilter
+	cuckooMagic = "hsc1"
+	// bucketSize the fingerprints of a bucket
+	bucketSize = 4
+	// maxKicks the relocations of an Add before the filter is full
+	maxKicks = 500
+)
+
+// CuckooFilter a probabilistic set of a fixed size, like BloomFilter,
+// holding a fingerprint of each item in one of its two buckets, so the
+// added items can be removed. Removing an item never added may remove
+// another item. The zero value holds nothing, make the filter with
+// NewCuckoo or UnmarshalBinary.
+type CuckooFilter struct {
+	// words the packed fingerprints of the bucket slots
+	words   []uint64
+	buckets uint64 // a power of two
+	bits    uint64 // the fingerprint bits
+	n       uint64
+	// victim the fingerprint and bucket evicted by the last failed
+	// relocation, the filter is full until it is placed
+	victim, victimAt uint64
+	rand             uint64
+	sync.RWMutex
+}
+
+// NewCuckoo instantiates a new empty cuckoo filter holding n items at
+// the false positive rate p, e.g. 0.01
+func NewCuckoo(n uint64, p float64) *CuckooFilter {
+	if n == 0 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+
+	// p is about 2 * bucketSize / 2^bits
+	bits := uint64(math.Ceil(math.Log2(2 * bucketSize / p)))
+	bits = min(max(bits, 4), 32)
+
+	// the buckets are 95% full at most
+	need := uint64(math.Ceil(float64(n) / (bucketSize * 0.95)))
+	buckets := uint64(1)
+	for buckets < need {
+		buckets <<= 1
+	}
+	return newCuckoo(buckets, bits)
+}
+
+func newCuckoo(buckets, bits uint64) *CuckooFilter {
+	return &CuckooFilter{
+		words:   make([]uint64, cuckooWords(buckets, bits)),
+		buckets: buckets,
+		bits:    bits,
+		rand:    0x9e3779b97f4a7c15,
+	}
+}
+
+// cuckooWords returns the words of the slots, with a padding word
+func cuckooWords(buckets, bits uint64) uint64 {
+	return (buckets*bucketSize*bits+63)/64 + 1
+}
+
+// slot returns the fingerprint of the slot s
+func (f *CuckooFilter) slot(s uint64) uint64 {
+	off := s * f.bits
+	w, shift := off/64, off%64
+	fp := f.words[w] >> shift
+	if shift+f.bits > 64 {
+		fp |= f.words[w+1] << (64 - shift)
+	}
+	return fp & (1<<f.bits - 1)
+}
+
+// setSlot sets the fingerprint of the slot s
+func (f *CuckooFilter) setSlot(s, fp uint64) {
+	mask := uint64(1)<<f.bits - 1
+	off := s * f.bits
+	w, shift := off/64, off%64
+	f.words[w] = f.words[w]&^(mask<<shift) | fp<<shift
+	if shift+f.bits > 64 {
+		f.words[w+1] = f.words[w+1]&^(mask>>(64-shift)) | fp>>(64-shift)
+	}
+}
+
+// locate returns the fingerprint and the two buckets of the item
+func (f *CuckooFilter) locate(item interface{}) (fp, i1, i2 uint64) {
+	h := filterHash(item)
+	fp = h >> (64 - f.bits)
+	if fp == 0 {
+		fp = 1
+	}
+	i1 = h & (f.buckets - 1)
+	return fp, i1, f.alt(i1, fp)
+}
+
+// alt returns the other bucket of the fingerprint in the bucket i
+func (f *CuckooFilter) alt(i, fp uint64) uint64 {
+	return (i ^ mix(fp)) & (f.buckets - 1)
+}
+
+// insert puts the fingerprint in a free slot of the bucket i
+func (f *CuckooFilter) insert(fp, i uint64) bool {
+	for s := i * bucketSize; s < (i+1)*bucketSize; s++ {
+		if f.slot(s) == 0 {
+			f.setSlot(s, fp)
+			return true
+		}
+	}
+	return false
+}
+
+// delete removes the fingerprint from the bucket i
+func (f *CuckooFilter) delete(fp, i uint64) bool {
+	for s := i * bucketSize; s < (i+1)*bucketSize; s++ {
+		if f.slot(s) == fp {
+			f.setSlot(s, 0)
+			return true
+		}
+	}
+	return false
+}
+
+// has reports whether the bucket i holds the fingerprint
+func (f *CuckooFilter) has(fp, i uint64) bool {
+	for s := i * bucketSize; s < (i+1)*bucketSize; s++ {
+		if f.slot(s) == fp {
+			return true
+		}
+	}
+	return false
+}
+
+// random returns the next xorshift number of the relocations
+func (f *CuckooFilter) random() uint64 {
+	f.rand ^= f.rand << 13
+	f.rand ^= f.rand >> 7
+	f.rand ^= f.rand << 17
+	return f.rand
+}
+
+// add puts the fingerprint in the bucket i or its other bucket,
+// relocating the fingerprints in the way
+func (f *CuckooFilter) add(fp, i uint64) error {
+	if f.victim != 0 {
+		return ErrFilterFull
+	}
+	if f.insert(fp, i) || f.insert(fp, f.alt(i, fp)) {
+		f.n++
+		return nil
+	}
+
+	if f.random()&1 == 1 {
+		i = f.alt(i, fp)
+	}
+	for kick := 0; kick < maxKicks; kick++ {
+		s := i*bucketSize + f.random()%bucketSize
+		evicted := f.slot(s)
+		f.setSlot(s, fp)
+		fp, i = evicted, f.alt(i, evicted)
+		if f.insert(fp, i) {
+			f.n++
+			return nil
+		}
+	}
+
+	// the item is in, but the last evicted fingerprint waits
+	f.victim, f.victimAt = fp, i
+	f.n++
+	return nil
+}
+
+// Add adds the items (one or more) to the filter, it returns
+// ErrFilterFull if the filter can not hold more items.
+func (f *CuckooFilter) Add(items ...interface{}) error {
+	f.Lock()
+	defer f.Unlock()
+	if f.buckets == 0 && len(items) > 0 {
+		return ErrFilterFull
+	}
+	for _, item := range items {
+		fp, i, _ := f.locate(item)
+		if err := f.add(fp, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MayContain returns false if the item is not in the filter, true if
+// it probably is.
+func (f *CuckooFilter) MayContain(item interface{}) bool {
+	f.RLock()
+	defer f.RUnlock()
+	if f.buckets == 0 {
+		return false
+	}
+
+	fp, i1, i2 := f.locate(item)
+	if f.victim == fp && (f.victimAt == i1 || f.victimAt == i2) {
+		return true
+	}
+	return f.has(fp, i1) || f.has(fp, i2)
+}
+
+// Remove removes an added item from the filter, it returns false if
+// the item is not in the filter.
+func (f *CuckooFilter) Remove(item interface{}) bool {
+	f.Lock()
+	defer f.Unlock()
+	if f.buckets == 0 {
+		return false
+	}
+
+	fp, i1, i2 := f.locate(item)
+	switch {
+	case f.victim == fp && (f.victimAt == i1 || f.victimAt == i2):
+		f.victim = 0
+	case f.delete(fp, i1) || f.delete(fp, i2):
+		if f.victim != 0 {
+			// the freed slot may take the waiting fingerprint
+			fp, i := f.victim, f.victimAt
+			f.victim = 0
+			f.n--
+			f.add(fp, i)
+		}
+	default:
+		return false
+	}
+	f.n--
+	return true
+}
+
+// Len returns the number of items in the filter, counting the
+// duplicates.
+func (f *CuckooFilter) Len() uint64 {
+	f.RLock()
+	defer f.RUnlock()
+	return f.n
+}
+
+// Cap returns the number of fingerprint slots and bits of the filter.
+func (f *CuckooFilter) Cap() (slots, bits uint64) {
+	f.RLock()
+	defer f.RUnlock()
+	return f.buckets * bucketSize, f.bits
+}
+
+// Clear removes all the items of the filter.
+func (f *CuckooFilter) Clear() {
+	f.Lock()
+	defer f.Unlock()
+	clear(f.words)
+	f.n, f.victim, f.victimAt = 0, 0, 0
+}
+
+// Merge adds the items of the other filter, of the same size, to the
+// filter, merging the filter into itself does nothing. It returns
+// ErrFilterFull if the filter can not hold them, with a part of them
+// added.
+func (f *CuckooFilter) Merge(other *CuckooFilter) error {
+	if other == f {
+		return nil
+	}
+
+	other.RLock()
+	src := &CuckooFilter{
+		words:   append([]uint64(nil), other.words...),
+		buckets: other.buckets,
+		bits:    other.bits,
+	}
+	victim, victimAt := other.victim, other.victimAt
+	other.RUnlock()
+
+	f.Lock()
+	defer f.Unlock()
+	if src.buckets != f.buckets || src.bits != f.bits {
+		return ErrFilterMismatch
+	}
+
+	for s := uint64(0); s < src.buckets*bucketSize; s++ {
+		if fp := src.slot(s); fp != 0 {
+			if err := f.add(fp, s/bucketSize); err != nil {
+				return err
+			}
+		}
+	}
+	if victim != 0 {
+		return f.add(victim, victimAt)
+	}
+	return nil
+}
+
+// MarshalBinary encodes the filter, see UnmarshalBinary
+func (f *CuckooFilter) MarshalBinary() ([]byte, error) {
+	f.RLock()
+	defer f.RUnlock()
+	data := make([]byte, 0, len(cuckooMagic)+40+8*len(f.words))
+	data = append(data, cuckooMagic...)
+	for _, n := range []uint64{f.buckets, f.bits, f.n, f.victim, f.victimAt} {
+		data = binary.LittleEndian.AppendUint64(data, n)
+	}
+	for _, w := range f.words {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes the filter encoded by MarshalBinary
+func (f *CuckooFilter) UnmarshalBinary(data []byte) error {
+	head := len(cuckooMagic) + 40
+	if len(data) < head || string(data[:len(cuckooMagic)]) != cuckooMagic {
+		return ErrFilterData
+	}
+
+	var h [5]uint64
+	for i := range h {
+		h[i] = binary.LittleEndian.Uint64(data[len(cuckooMagic)+8*i:])
+	}
+	buckets, bits := h[0], h[1]
+	if buckets == 0 || buckets&(buckets-1) != 0 || bits < 4 || bits > 32 ||
+		buckets > uint64(len(data)) || h[4] >= buckets ||
+		uint64(len(data)-head) != cuckooWords(buckets, bits)*8 {
+		return ErrFilterData
+	}
+
+	g := newCuckoo(buckets, bits)
+	for i := range g.words {
+		g.words[i] = binary.LittleEndian.Uint64(data[head+8*i:])
+	}
+
+	f.Lock()
+	defer f.Unlock()
+	f.words, f.buckets, f.bits = g.words, buckets, bits
+	f.n, f.victim, f.victimAt = h[2], h[3], h[4]
+	if f.rand == 0 {
+		f.rand = g.rand
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package hset
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+func TestCuckoo(t *testing.T) {
+	for _, p := range []float64{0.1, 0.01, 0.001} {
+		f := NewCuckoo(10000, p)
+		for i := 0; i < 10000; i++ {
+			tt.Nil(t, f.Add("item/"+strconv.Itoa(i)))
+		}
+		tt.Equal(t, uint64(10000), f.Len())
+
+		for i := 0; i < 10000; i++ {
+			tt.True(t, f.MayContain("item/"+strconv.Itoa(i)))
+		}
+		rate := falsePositives(100000, f.MayContain)
+		tt.True(t, rate <= p)
+	}
+
+	f := NewCuckoo(100, 0.01)
+	f.Add("a", "b", 1, 0.0)
+	tt.True(t, f.MayContain(-0.0))
+	tt.True(t, f.Remove("a"))
+	tt.False(t, f.MayContain("a"))
+	tt.False(t, f.Remove("a"))
+	tt.True(t, f.MayContain("b"))
+	tt.Equal(t, uint64(3), f.Len())
+
+	f.Clear()
+	tt.False(t, f.MayContain("b"))
+	tt.Equal(t, uint64(0), f.Len())
+}
+
+func TestCuckooFull(t *testing.T) {
+	f := NewCuckoo(8, 0.01)
+	slots, bits := f.Cap()
+	tt.Equal(t, uint64(16), slots)
+	tt.Equal(t, uint64(10), bits)
+
+	var err error
+	n := 0
+	for ; err == nil; n++ {
+		err = f.Add(n)
+	}
+	tt.Equal(t, ErrFilterFull, err)
+	tt.True(t, n > 8)
+	for i := 0; i < n-1; i++ {
+		tt.True(t, f.MayContain(i))
+	}
+
+	for i := 0; i < n-1; i++ {
+		tt.True(t, f.Remove(i))
+	}
+	tt.Equal(t, uint64(0), f.Len())
+	tt.Nil(t, f.Add("a"))
+}
+
+func TestCuckooMerge(t *testing.T) {
+	a, b := NewCuckoo(1000, 0.01), NewCuckoo(1000, 0.01)
+	a.Add("a", "b")
+	b.Add("c")
+	tt.Nil(t, a.Merge(b))
+	tt.True(t, a.MayContain("a"))
+	tt.True(t, a.MayContain("c"))
+	tt.Equal(t, uint64(3), a.Len())
+
+	tt.True(t, a.Remove("c"))
+	tt.True(t, b.MayContain("c"))
+	tt.Equal(t, ErrFilterMismatch, a.Merge(NewCuckoo(10, 0.01)))
+
+	tt.Nil(t, a.Merge(a))
+	tt.Equal(t, uint64(2), a.Len())
+	tt.True(t, a.Remove("a"))
+	tt.False(t, a.MayContain("a"))
+}
+
+func TestCuckooBinary(t *testing.T) {
+	f := NewCuckoo(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add(i)
+	}
+	data, err := f.MarshalBinary()
+	tt.Nil(t, err)
+
+	var g CuckooFilter
+	tt.Nil(t, g.UnmarshalBinary(data))
+	tt.Equal(t, f.Len(), g.Len())
+	for i := 0; i < 1000; i++ {
+		tt.True(t, g.MayContain(i))
+	}
+	tt.True(t, g.Remove(5))
+	tt.Nil(t, g.Add("a"))
+
+	tt.Equal(t, ErrFilterData, g.UnmarshalBinary(data[:len(data)-1]))
+	tt.Equal(t, ErrFilterData, g.UnmarshalBinary([]byte("hsb1")))
+}
+
+func TestCuckooZero(t *testing.T) {
+	var f CuckooFilter
+	tt.False(t, f.MayContain(1))
+	tt.Equal(t, ErrFilterFull, f.Add(1))
+	tt.False(t, f.Remove(1))
+	tt.Equal(t, uint64(0), f.Len())
+}
@@ -0,0 +1,315 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package hset
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// DefaultSweep the interval of the background expiry of NewExpiring
+const DefaultSweep = time.Second
+
+// ExpiringSet holds elements with a time-to-live. The expired items are
+// removed lazily when the set is used, and by a background sweep until
+// Stop is called.
+type ExpiringSet struct {
+	items    map[interface{}]expiry
+	deadline expiryHeap
+	ttl      time.Duration
+	onExpire func(item interface{})
+	now      func() time.Time
+	sync.Mutex
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+var _ Set = (*ExpiringSet)(nil)
+
+// expiry the deadline and the time-to-live of an item,
+// a zero deadline never expires
+type expiry struct {
+	deadline time.Time
+	ttl      time.Duration
+}
+
+// expiryItem an item deadline in the heap, stale if the item
+// was removed or got a new deadline
+type expiryItem struct {
+	item     interface{}
+	deadline time.Time
+}
+
+// expiryHeap a min-heap of the item deadlines
+type expiryHeap []expiryItem
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].deadline.Before(h[j].deadline) }
+func (h expiryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *expiryHeap) Push(x any)        { *h = append(*h, x.(expiryItem)) }
+func (h *expiryHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// NewExpiring instantiates a new empty expiring set, Add uses the ttl
+// and the background sweep runs every interval, DefaultSweep by default
+func NewExpiring(ttl time.Duration, interval ...time.Duration) *ExpiringSet {
+	sweep := DefaultSweep
+	if len(interval) > 0 && interval[0] > 0 {
+		sweep = interval[0]
+	}
+
+	set := &ExpiringSet{
+		items: make(map[interface{}]expiry),
+		ttl:   ttl,
+		now:   time.Now,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go set.run(sweep)
+	return set
+}
+
+// run sweep the expired items every interval until Stop is called
+func (set *ExpiringSet) run(interval time.Duration) {
+	defer close(set.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-set.stop:
+			return
+		case <-ticker.C:
+			set.Lock()
+			expired := set.expire()
+			set.Unlock()
+			set.expired(expired)
+		}
+	}
+}
+
+// Stop stops the background sweep, the expired items are still
+// removed lazily.
+func (set *ExpiringSet) Stop() {
+	set.stopOnce.Do(func() {
+		close(set.stop)
+	})
+	<-set.done
+}
+
+// OnExpire sets the callback called with each expired item, outside
+// of the set lock, it is not called for the removed items.
+func (set *ExpiringSet) OnExpire(fn func(item interface{})) {
+	set.Lock()
+	set.onExpire = fn
+	set.Unlock()
+}
+
+// expire removes the expired items and returns them, the set is locked
+func (set *ExpiringSet) expire() []interface{} {
+	now := set.now()
+
+	var expired []interface{}
+	for len(set.deadline) > 0 && !set.deadline[0].deadline.After(now) {
+		top := heap.Pop(&set.deadline).(expiryItem)
+		if e, ok := set.items[top.item]; ok && e.deadline.Equal(top.deadline) {
+			delete(set.items, top.item)
+			expired = append(expired, top.item)
+		}
+	}
+
+	// drop the stale deadlines of the touched and removed items
+	if len(set.deadline) > 2*len(set.items)+64 {
+		set.deadline = set.deadline[:0]
+		for item, e := range set.items {
+			if !e.deadline.IsZero() {
+				set.deadline = append(set.deadline, expiryItem{item, e.deadline})
+			}
+		}
+		heap.Init(&set.deadline)
+	}
+	return expired
+}
+
+// expired calls the OnExpire callback with the expired items
+func (set *ExpiringSet) expired(items []interface{}) {
+	if len(items) == 0 {
+		return
+	}
+
+	set.Lock()
+	fn := set.onExpire
+	set.Unlock()
+	if fn == nil {
+		return
+	}
+	for _, item := range items {
+		fn(item)
+	}
+}
+
+// do runs fn with the set locked and the expired items removed
+func (set *ExpiringSet) do(fn func()) {
+	set.Lock()
+	expired := set.expire()
+	fn()
+	set.Unlock()
+	set.expired(expired)
+}
+
+// put sets the item expiry, the set is locked
+func (set *ExpiringSet) put(item interface{}, ttl time.Duration) {
+	e := expiry{ttl: ttl}
+	if ttl > 0 {
+		e.deadline = set.now().Add(ttl)
+		heap.Push(&set.deadline, expiryItem{item, e.deadline})
+	}
+	set.items[item] = e
+}
+
+// Add adds the items (one or more) to the set with the set ttl,
+// the items already in the set get a new deadline.
+func (set *ExpiringSet) Add(items ...interface{}) {
+	set.do(func() {
+		for _, item := range items {
+			set.put(item, set.ttl)
+		}
+	})
+}
+
+// AddWithTTL adds the item to the set for the ttl, or without
+// expiry if ttl <= 0.
+func (set *ExpiringSet) AddWithTTL(item interface{}, ttl time.Duration) {
+	set.do(func() {
+		set.put(item, ttl)
+	})
+}
+
+// Touch refreshes the deadline of the item with its ttl, it returns
+// false if the item is not in the set.
+func (set *ExpiringSet) Touch(item interface{}) bool {
+	ok := false
+	set.do(func() {
+		var e expiry
+		if e, ok = set.items[item]; ok {
+			set.put(item, e.ttl)
+		}
+	})
+	return ok
+}
+
+// TTL returns the time left before the item expires, 0 if it does not
+// expire, false if the item is not in the set.
+func (set *ExpiringSet) TTL(item interface{}) (time.Duration, bool) {
+	var left time.Duration
+	ok := false
+	set.do(func() {
+		var e expiry
+		if e, ok = set.items[item]; ok && !e.deadline.IsZero() {
+			left = e.deadline.Sub(set.now())
+		}
+	})
+	return left, ok
+}
+
+// Remove removes the items (one or more) from the set.
+func (set *ExpiringSet) Remove(items ...interface{}) {
+	set.do(func() {
+		for _, item := range items {
+			delete(set.items, item)
+		}
+	})
+}
+
+// Clear clears all values in the set.
+func (set *ExpiringSet) Clear() {
+	set.Lock()
+	set.items = make(map[interface{}]expiry)
+	set.deadline = nil
+	set.Unlock()
+}
+
+// Contains check if items (one or more) are present in the set.
+// All items have to be present in the set for the method to return true.
+// Returns true if no arguments are passed at all.
+func (set *ExpiringSet) Contains(items ...interface{}) bool {
+	ok := true
+	set.do(func() {
+		for _, item := range items {
+			if _, ok = set.items[item]; !ok {
+				return
+			}
+		}
+	})
+	return ok
+}
+
+// Empty returns true if set does not contain any elements.
+func (set *ExpiringSet) Empty() bool {
+	return set.Len() == 0
+}
+
+// Len returns number of elements within the set.
+func (set *ExpiringSet) Len() int {
+	size := 0
+	set.do(func() {
+		size = len(set.items)
+	})
+	return size
+}
+
+// Values returns all items in the set.
+func (set *ExpiringSet) Values() []interface{} {
+	var values []interface{}
+	set.do(func() {
+		values = make([]interface{}, 0, len(set.items))
+		for item := range set.items {
+			values = append(values, item)
+		}
+	})
+	return values
+}
+
+// Same to determine whether the two sets hold the same items.
+func (set *ExpiringSet) Same(other Set) bool {
+	if other == nil {
+		return false
+	}
+
+	values := other.Values()
+	same := true
+	set.do(func() {
+		if len(set.items) != len(values) {
+			same = false
+			return
+		}
+		for _, item := range values {
+			if _, ok := set.items[item]; !ok {
+				same = false
+				return
+			}
+		}
+	})
+	return same
+}
+
+// String returns a string representation of container,
+// the items are sorted by Compare
+func (set *ExpiringSet) String() string {
+	return formatSorted(set.Values())
+}
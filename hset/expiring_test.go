@@ -0,0 +1,129 @@
+package hset
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vcaesar/tt"
+)
+
+// clock a fake time for the expiring set tests
+type clock struct {
+	sync.Mutex
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.t
+}
+
+func (c *clock) add(d time.Duration) {
+	c.Lock()
+	c.t = c.t.Add(d)
+	c.Unlock()
+}
+
+func newExpiring(ttl time.Duration) (*ExpiringSet, *clock) {
+	c := &clock{t: time.Unix(0, 0)}
+	set := NewExpiring(ttl, time.Hour)
+	set.now = c.now
+	return set, c
+}
+
+func TestExpiring(t *testing.T) {
+	set, c := newExpiring(time.Minute)
+	defer set.Stop()
+
+	var expired []interface{}
+	set.OnExpire(func(item interface{}) {
+		expired = append(expired, item)
+	})
+
+	set.Add("a", "b")
+	set.AddWithTTL("c", 3*time.Minute)
+	set.AddWithTTL("d", 0)
+	tt.Equal(t, 4, set.Len())
+	tt.Equal(t, "a, b, c, d", set.String())
+
+	left, ok := set.TTL("c")
+	tt.True(t, ok)
+	tt.Equal(t, 3*time.Minute, left)
+	left, ok = set.TTL("d")
+	tt.True(t, ok)
+	tt.Equal(t, time.Duration(0), left)
+
+	c.add(30 * time.Second)
+	tt.True(t, set.Touch("a"))
+	tt.False(t, set.Touch("x"))
+
+	c.add(30 * time.Second)
+	tt.True(t, set.Contains("a"))
+	tt.False(t, set.Contains("b"))
+	tt.Equal(t, []interface{}{"b"}, expired)
+
+	c.add(time.Hour)
+	tt.Equal(t, []interface{}{"d"}, set.Values())
+	tt.Equal(t, 3, len(expired))
+
+	set.Remove("d")
+	tt.True(t, set.Empty())
+	tt.Equal(t, 3, len(expired))
+
+	linked := NewLinkedSet()
+	set.Add(1, 2)
+	linked.Add(2, 1)
+	tt.True(t, set.Same(linked))
+	set.Clear()
+	tt.False(t, set.Same(linked))
+}
+
+func TestExpiringDedup(t *testing.T) {
+	set, c := newExpiring(10 * time.Minute)
+	defer set.Stop()
+
+	seen := func(id string) bool {
+		if set.Contains(id) {
+			return true
+		}
+		set.Add(id)
+		return false
+	}
+
+	tt.False(t, seen("msg-1"))
+	tt.True(t, seen("msg-1"))
+	c.add(11 * time.Minute)
+	tt.False(t, seen("msg-1"))
+
+	// the stale deadlines of the re-added items are dropped
+	for i := 0; i < 1000; i++ {
+		set.Add("msg-1")
+	}
+	set.Len()
+	tt.True(t, len(set.deadline) < 100)
+}
+
+func TestExpiringSweep(t *testing.T) {
+	set := NewExpiring(10*time.Millisecond, time.Millisecond)
+
+	done := make(chan interface{}, 1)
+	set.OnExpire(func(item interface{}) {
+		done <- item
+	})
+	set.Add("a")
+
+	select {
+	case item := <-done:
+		tt.Equal(t, "a", item)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the item did not expire")
+	}
+
+	set.Stop()
+	set.Stop()
+	set.AddWithTTL("b", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	tt.False(t, set.Contains("b"))
+}
@@ -0,0 +1,109 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+package hset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+)
+
+var (
+	// ErrFilterMismatch the filters of a Merge differ in size or kind
+	ErrFilterMismatch = errors.New("hset: filters of different sizes")
+	// ErrFilterFull a cuckoo filter can not hold more items
+	ErrFilterFull = errors.New("hset: filter is full")
+	// ErrFilterData the binary data is not a filter of this kind
+	ErrFilterData = errors.New("hset: invalid filter data")
+)
+
+// filterHash returns the 64-bit FNV-1a hash of the item, stable across
+// processes so the saved filters can be reloaded. Equal numbers of
+// different types hash differently, like they are different Hset items.
+// The pointers and channels are hashed by address, so they do not
+// match once the filter is reloaded.
+func filterHash(item interface{}) uint64 {
+	var (
+		buf [9]byte
+		b   []byte
+	)
+	number := func(kind byte, n uint64) []byte {
+		buf[0] = kind
+		binary.LittleEndian.PutUint64(buf[1:], n)
+		return buf[:]
+	}
+
+	switch v := item.(type) {
+	case string:
+		b = append([]byte{'s'}, v...)
+	case []byte:
+		b = append([]byte{'s'}, v...)
+	case int:
+		b = number('i', uint64(v))
+	case int8:
+		b = number(1, uint64(v))
+	case int16:
+		b = number(2, uint64(v))
+	case int32:
+		b = number(3, uint64(v))
+	case int64:
+		b = number(4, uint64(v))
+	case uint:
+		b = number('u', uint64(v))
+	case uint8:
+		b = number(5, uint64(v))
+	case uint16:
+		b = number(6, uint64(v))
+	case uint32:
+		b = number(7, uint64(v))
+	case uint64:
+		b = number(8, v)
+	case float32:
+		// -0 and +0 are equal items
+		if v == 0 {
+			v = 0
+		}
+		b = number(9, uint64(math.Float32bits(v)))
+	case float64:
+		if v == 0 {
+			v = 0
+		}
+		b = number('f', math.Float64bits(v))
+	case bool:
+		n := uint64(0)
+		if v {
+			n = 1
+		}
+		b = number('b', n)
+	default:
+		var w bytes.Buffer
+		rv := reflect.ValueOf(item)
+		if rv.IsValid() {
+			w.WriteString(rv.Type().String())
+		}
+		if rv.Kind() == reflect.Slice {
+			for i := 0; i < rv.Len(); i++ {
+				hashValue(&w, rv.Index(i))
+			}
+		} else {
+			hashValue(&w, rv)
+		}
+		b = w.Bytes()
+	}
+
+	h := uint64(14695981039346656037)
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= 1099511628211
+	}
+	return mix(h)
+}
@@ -1,6 +1,7 @@
 package hset
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/vcaesar/tt"
@@ -30,3 +31,126 @@ func BenchmarkRemove(b *testing.B) {
 
 	tt.BM(b, fn)
 }
+
+var (
+	treeSet   = NewTreeSet(nil)
+	linkedSet = NewLinkedSet()
+)
+
+func BenchmarkContains(b *testing.B) {
+	set.Add(1, 2, 3)
+	fn := func() {
+		set.Contains(2)
+		set.Contains(4)
+	}
+
+	tt.BM(b, fn)
+}
+
+func BenchmarkTreeSetAdd(b *testing.B) {
+	fn := func() {
+		treeSet.Add()
+		treeSet.Add(1)
+		treeSet.Add(2)
+		treeSet.Add(2, 3)
+		treeSet.Add()
+	}
+
+	tt.BM(b, fn)
+}
+
+func BenchmarkTreeSetRemove(b *testing.B) {
+	fn := func() {
+		treeSet.Remove(3)
+		treeSet.Remove(3)
+		treeSet.Remove()
+		treeSet.Remove(2)
+	}
+
+	tt.BM(b, fn)
+}
+
+func BenchmarkTreeSetContains(b *testing.B) {
+	treeSet.Add(1, 2, 3)
+	fn := func() {
+		treeSet.Contains(2)
+		treeSet.Contains(4)
+	}
+
+	tt.BM(b, fn)
+}
+
+func BenchmarkLinkedSetAdd(b *testing.B) {
+	fn := func() {
+		linkedSet.Add()
+		linkedSet.Add(1)
+		linkedSet.Add(2)
+		linkedSet.Add(2, 3)
+		linkedSet.Add()
+	}
+
+	tt.BM(b, fn)
+}
+
+func BenchmarkLinkedSetRemove(b *testing.B) {
+	fn := func() {
+		linkedSet.Remove(3)
+		linkedSet.Remove(3)
+		linkedSet.Remove()
+		linkedSet.Remove(2)
+	}
+
+	tt.BM(b, fn)
+}
+
+func BenchmarkLinkedSetContains(b *testing.B) {
+	linkedSet.Add(1, 2, 3)
+	fn := func() {
+		linkedSet.Contains(2)
+		linkedSet.Contains(4)
+	}
+
+	tt.BM(b, fn)
+}
+
+// parallel adds and checks the urls on the set from parallel goroutines
+func parallel(b *testing.B, add func(item interface{}), exists func(item interface{}) bool) {
+	urls := make([]string, 1024)
+	for i := range urls {
+		urls[i] = "https://example.com/" + strconv.Itoa(i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			url := urls[i%len(urls)]
+			if !exists(url) {
+				add(url)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkParallelHset(b *testing.B) {
+	set := New()
+	parallel(b, func(item interface{}) { set.Add(item) }, set.Exists)
+}
+
+func BenchmarkParallelSharded(b *testing.B) {
+	set := NewSharded()
+	parallel(b, func(item interface{}) { set.Add(item) }, set.Exists)
+}
+
+func BenchmarkParallelHsetAdd(b *testing.B) {
+	set := New()
+	parallel(b, func(item interface{}) { set.Add(item) },
+		func(interface{}) bool { return false })
+}
+
+func BenchmarkParallelShardedAdd(b *testing.B) {
+	set := NewSharded()
+	parallel(b, func(item interface{}) { set.Add(item) },
+		func(interface{}) bool { return false })
+}
@@ -10,143 +10,46 @@
 
 package hset
 
-import (
-	"fmt"
-	"strings"
-	"sync"
-)
-
-// Hset holds elements in go's native map
+// Hset holds elements in go's native map,
+// it is the interface{} hset, use Of for the typed hsets.
+// Its String takes the optional prefix flag, so it is not a Set,
+// use AsSet to swap it with the other Set implementations.
 type Hset struct {
-	items map[interface{}]struct{}
-	// items sync.Map[interface{}]struct{}
-	sync.RWMutex
+	Of[interface{}]
 }
 
-// Hset holds elements in go's native map
-var itemExists = struct{}{}
-
-// New instantiates a new empty hset
-func New() *Hset {
-	return &Hset{items: make(map[interface{}]struct{})}
+// hsetSet the Set of an Hset
+type hsetSet struct {
+	*Hset
 }
 
-// Add adds the items (one or more) to the hset.
-func (hset *Hset) Add(items ...interface{}) {
-	hset.Lock()
-	// defer hset.Unlock()
-	for _, item := range items {
-		hset.items[item] = itemExists
-	}
-	hset.Unlock()
-}
+var _ Set = hsetSet{}
 
-// Remove removes the items (one or more) from the hset.
-func (hset *Hset) Remove(items ...interface{}) {
-	hset.Lock()
-	// defer hset.Unlock()
-	for _, item := range items {
-		delete(hset.items, item)
-	}
-	hset.Unlock()
-}
-
-// Contains check if items (one or more) are present in the hset.
-// All items have to be present in the hset for the method to return true.
-// Returns true if no arguments are passed at all,
-// i.e. hset is always superhset of empty hset.
-func (hset *Hset) Contains(items ...interface{}) bool {
-	for _, item := range items {
-		if _, contains := hset.items[item]; !contains {
-			return false
-		}
-	}
-	return true
-}
-
-// Clear clears all values in the hset.
-func (hset *Hset) Clear() {
-	hset.Lock()
-	// defer hset.Unlock()
-	hset.items = make(map[interface{}]struct{})
-	hset.Unlock()
-}
-
-// Exists returns a bool indicating if the given item exists in the set.
-func (hset *Hset) Exists(item interface{}) bool {
-	hset.RLock()
-	_, ok := hset.items[item]
-	hset.RUnlock()
-
-	return ok
-}
-
-// Empty returns true if hset does not contain any elements.
-func (hset *Hset) Empty() bool {
-	// return hset.Size() == 0
-	return hset.Len() == 0
-}
-
-// Size returns number of elements within the hset.
-// func (hset *Hset) Size() int {
-// 	return len(hset.items)
-// }
-
-// Len returns number of elements within the hset.
-func (hset *Hset) Len() int {
-	hset.RLock()
-	size := len(hset.items)
-	hset.RUnlock()
-	return size
+// String returns a string representation of container
+func (set hsetSet) String() string {
+	return set.Hset.String()
 }
 
-// Values returns all items in the hset.
-// List()
-func (hset *Hset) Values() []interface{} {
-	hset.RLock()
-	defer hset.RUnlock()
+// Hset holds elements in go's native map
+var itemExists = struct{}{}
 
-	// values := make([]interface{}, hset.Size())
-	values := make([]interface{}, hset.Len())
-	count := 0
-	for item := range hset.items {
-		values[count] = item
-		count++
-	}
-	return values
+// New instantiates a new empty hset
+func New() *Hset {
+	return &Hset{Of: Of[interface{}]{items: make(map[interface{}]struct{})}}
 }
 
 // Same to determine whether the two hset type values are the same.
 func (hset *Hset) Same(other Set) bool {
-	hset.RLock()
-	defer hset.RUnlock()
-
 	if other == nil {
 		return false
 	}
-	if hset.Len() != other.Len() {
-		return false
-	}
 
-	for key := range hset.items {
-		if !other.Contains(key) {
-			return false
-		}
-	}
-	return true
+	// copy other first, so the two hsets are never locked together
+	return hset.same(other.Values())
 }
 
-// String returns a string representation of container
-func (hset *Hset) String(pre ...bool) string {
-	str := ""
-	if len(pre) > 0 {
-		str = "Has Hset:\n"
-	}
-	items := []string{}
-	for k := range hset.items {
-		items = append(items, fmt.Sprintf("%v", k))
-	}
-
-	str += strings.Join(items, ", ")
-	return str
+// AsSet returns the hset as a Set sharing its items, e.g. to swap it
+// with a TreeSet or a LinkedSet
+func (hset *Hset) AsSet() Set {
+	return hsetSet{hset}
 }
@@ -0,0 +1,177 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package hset
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Of holds elements of type T in go's native map,
+// the zero value is an empty hset ready to use
+type Of[T comparable] struct {
+	items map[T]struct{}
+	sync.RWMutex
+}
+
+// NewOf instantiates a new hset of the items
+func NewOf[T comparable](items ...T) *Of[T] {
+	hset := &Of[T]{items: make(map[T]struct{}, len(items))}
+	for _, item := range items {
+		hset.items[item] = itemExists
+	}
+	return hset
+}
+
+// Add adds the items (one or more) to the hset.
+func (hset *Of[T]) Add(items ...T) {
+	hset.Lock()
+	if hset.items == nil {
+		hset.items = make(map[T]struct{}, len(items))
+	}
+	for _, item := range items {
+		hset.items[item] = itemExists
+	}
+	hset.Unlock()
+}
+
+// Remove removes the items (one or more) from the hset.
+func (hset *Of[T]) Remove(items ...T) {
+	hset.Lock()
+	for _, item := range items {
+		delete(hset.items, item)
+	}
+	hset.Unlock()
+}
+
+// Contains check if items (one or more) are present in the hset.
+// All items have to be present in the hset for the method to return true.
+// Returns true if no arguments are passed at all,
+// i.e. hset is always superhset of empty hset.
+func (hset *Of[T]) Contains(items ...T) bool {
+	hset.RLock()
+	defer hset.RUnlock()
+
+	for _, item := range items {
+		if _, contains := hset.items[item]; !contains {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear clears all values in the hset.
+func (hset *Of[T]) Clear() {
+	hset.Lock()
+	hset.items = make(map[T]struct{})
+	hset.Unlock()
+}
+
+// Exists returns a bool indicating if the given item exists in the set.
+func (hset *Of[T]) Exists(item T) bool {
+	hset.RLock()
+	_, ok := hset.items[item]
+	hset.RUnlock()
+
+	return ok
+}
+
+// Empty returns true if hset does not contain any elements.
+func (hset *Of[T]) Empty() bool {
+	return hset.Len() == 0
+}
+
+// Len returns number of elements within the hset.
+func (hset *Of[T]) Len() int {
+	hset.RLock()
+	size := len(hset.items)
+	hset.RUnlock()
+	return size
+}
+
+// Values returns all items in the hset.
+func (hset *Of[T]) Values() []T {
+	hset.RLock()
+	defer hset.RUnlock()
+
+	values := make([]T, 0, len(hset.items))
+	for item := range hset.items {
+		values = append(values, item)
+	}
+	return values
+}
+
+// Same to determine whether the two hset type values are the same.
+func (hset *Of[T]) Same(other *Of[T]) bool {
+	if other == nil {
+		return false
+	}
+	if other == hset {
+		return true
+	}
+
+	// copy other first, so the two hsets are never locked together
+	return hset.same(other.Values())
+}
+
+// same reports whether the hset holds exactly the distinct values
+func (hset *Of[T]) same(values []T) bool {
+	hset.RLock()
+	defer hset.RUnlock()
+
+	if len(hset.items) != len(values) {
+		return false
+	}
+	for _, item := range values {
+		if _, ok := hset.items[item]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of container,
+// the items are sorted by Compare
+func (hset *Of[T]) String(pre ...bool) string {
+	str := ""
+	if len(pre) > 0 {
+		str = "Has Hset:\n"
+	}
+	return str + formatSorted(hset.Values())
+}
+
+// ToJSON outputs the JSON representation of hset's elements.
+func (hset *Of[T]) ToJSON() ([]byte, error) {
+	return json.Marshal(hset.Values())
+}
+
+// FromJSON populates hset's elements from the input JSON representation,
+// decoded as T.
+func (hset *Of[T]) FromJSON(data []byte) error {
+	elements := []T{}
+	err := json.Unmarshal(data, &elements)
+	if err == nil {
+		hset.Clear()
+		hset.Add(elements...)
+	}
+
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, see ToJSON
+func (hset *Of[T]) MarshalJSON() ([]byte, error) {
+	return hset.ToJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler, see FromJSON
+func (hset *Of[T]) UnmarshalJSON(data []byte) error {
+	return hset.FromJSON(data)
+}
@@ -0,0 +1,73 @@
+package hset
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+func TestOf(t *testing.T) {
+	set := NewOf(3, 1, 2)
+	set.Add(2, 4)
+	tt.Equal(t, 4, set.Len())
+	tt.True(t, set.Contains(1, 2, 3, 4))
+	tt.False(t, set.Contains(5))
+	tt.True(t, set.Exists(4))
+
+	set.Remove(4, 5)
+	tt.Equal(t, 3, set.Len())
+
+	sum := 0
+	for _, v := range set.Values() {
+		sum += v
+	}
+	tt.Equal(t, 6, sum)
+
+	tt.True(t, set.Same(NewOf(1, 2, 3)))
+	tt.True(t, set.Same(set))
+	tt.False(t, set.Same(NewOf(1, 2)))
+	tt.False(t, set.Same(nil))
+
+	set.Clear()
+	tt.True(t, set.Empty())
+
+	var zero Of[string]
+	zero.Add("a")
+	tt.True(t, zero.Contains("a"))
+	tt.Equal(t, "a", zero.String())
+}
+
+func TestOfJSON(t *testing.T) {
+	set := NewOf(1, 2)
+	data, err := set.ToJSON()
+	tt.Nil(t, err)
+
+	got := NewOf[int]()
+	tt.Nil(t, got.FromJSON(data))
+	tt.True(t, got.Contains(1, 2))
+	tt.True(t, got.Same(set))
+	tt.NotNil(t, got.FromJSON([]byte(`["a"]`)))
+
+	conf := struct {
+		IDs *Of[int64] `json:"ids"`
+	}{}
+	tt.Nil(t, json.Unmarshal([]byte(`{"ids": [7, 8]}`), &conf))
+	tt.True(t, conf.IDs.Contains(7, 8))
+
+	data, err = json.Marshal(conf)
+	tt.Nil(t, err)
+	tt.True(t, string(data) == `{"ids":[7,8]}` || string(data) == `{"ids":[8,7]}`)
+}
+
+func TestHsetJSON(t *testing.T) {
+	set := New()
+	set.Add(1, "a")
+	data, err := set.ToJSON()
+	tt.Nil(t, err)
+
+	got := New()
+	tt.Nil(t, got.FromJSON(data))
+	tt.True(t, got.Contains(float64(1), "a"))
+	tt.False(t, got.Contains(1))
+}
@@ -82,3 +82,22 @@ func TestSetString(t *testing.T) {
 
 	tt.Equal(t, 5, len(s1))
 }
+
+func TestAsSet(t *testing.T) {
+	hset := New()
+	set := hset.AsSet()
+	set.Add(2, "a", 1)
+	tt.True(t, hset.Contains(1, 2, "a"))
+	tt.Equal(t, "1, 2, a", set.String())
+
+	for _, other := range []Set{NewTreeSet(nil), NewLinkedSet(), NewSharded()} {
+		other.Add(1, 2, "a")
+		tt.True(t, set.Same(other))
+		tt.True(t, other.Same(set))
+	}
+
+	set.Remove(2)
+	tt.Equal(t, 2, hset.Len())
+	set.Clear()
+	tt.True(t, hset.Empty())
+}
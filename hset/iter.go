@@ -0,0 +1,249 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package hset
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// All returns an iterator over the items of the hset, in no order,
+// e.g. for item := range hset.All().
+// The hset is read locked during the iteration, so the loop body
+// must not modify it.
+func (hset *Of[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		hset.RLock()
+		defer hset.RUnlock()
+
+		for item := range hset.items {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Each calls fn for each item of the hset, the hset is read locked
+// so fn must not modify it.
+func (hset *Of[T]) Each(fn func(item T)) {
+	for item := range hset.All() {
+		fn(item)
+	}
+}
+
+// Filter returns a new hset of the items matching pred.
+func (hset *Of[T]) Filter(pred func(item T) bool) *Of[T] {
+	return &Of[T]{items: hset.filter(pred)}
+}
+
+func (hset *Of[T]) filter(pred func(item T) bool) map[T]struct{} {
+	m := make(map[T]struct{})
+	for item := range hset.All() {
+		if pred(item) {
+			m[item] = itemExists
+		}
+	}
+	return m
+}
+
+// Map returns a new hset of the items mapped by fn.
+func Map[T, U comparable](hset *Of[T], fn func(item T) U) *Of[U] {
+	m := make(map[U]struct{})
+	for item := range hset.All() {
+		m[fn(item)] = itemExists
+	}
+	return &Of[U]{items: m}
+}
+
+// Any returns true if an item of the hset matches pred.
+func (hset *Of[T]) Any(pred func(item T) bool) bool {
+	for item := range hset.All() {
+		if pred(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// Every returns true if all the items of the hset match pred,
+// true for the empty hset.
+func (hset *Of[T]) Every(pred func(item T) bool) bool {
+	for item := range hset.All() {
+		if !pred(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// Partition returns the new hsets of the items matching pred,
+// and of the others.
+func (hset *Of[T]) Partition(pred func(item T) bool) (*Of[T], *Of[T]) {
+	in, out := hset.partition(pred)
+	return &Of[T]{items: in}, &Of[T]{items: out}
+}
+
+func (hset *Of[T]) partition(pred func(item T) bool) (map[T]struct{}, map[T]struct{}) {
+	in, out := make(map[T]struct{}), make(map[T]struct{})
+	for item := range hset.All() {
+		if pred(item) {
+			in[item] = itemExists
+		} else {
+			out[item] = itemExists
+		}
+	}
+	return in, out
+}
+
+// Sorted returns the items of the hset sorted by less.
+func (hset *Of[T]) Sorted(less func(a, b T) bool) []T {
+	values := hset.Values()
+	sort.Slice(values, func(i, j int) bool {
+		return less(values[i], values[j])
+	})
+	return values
+}
+
+// Filter returns a new hset of the items matching pred.
+func (hset *Hset) Filter(pred func(item interface{}) bool) *Hset {
+	return &Hset{Of: Of[interface{}]{items: hset.filter(pred)}}
+}
+
+// Partition returns the new hsets of the items matching pred,
+// and of the others.
+func (hset *Hset) Partition(pred func(item interface{}) bool) (*Hset, *Hset) {
+	in, out := hset.partition(pred)
+	return &Hset{Of: Of[interface{}]{items: in}}, &Hset{Of: Of[interface{}]{items: out}}
+}
+
+// Compare returns -1, 0 or 1 as the item a sorts before, with or
+// after b: nil first, then the numbers by value, the bools, the
+// strings, and the other items by their type and %v representation.
+func Compare(a, b interface{}) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if ra, rb := rank(va), rank(vb); ra != rb {
+		return order(ra, rb)
+	}
+
+	switch rank(va) {
+	case 0:
+		return 0
+	case 1:
+		return compareNumbers(va, vb)
+	case 2:
+		return order(boolInt(va.Bool()), boolInt(vb.Bool()))
+	case 3:
+		return order(va.String(), vb.String())
+	}
+
+	if ta, tb := va.Type().String(), vb.Type().String(); ta != tb {
+		return order(ta, tb)
+	}
+	return order(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
+
+// formatSorted returns the items sorted by Compare and joined,
+// the String of the unordered sets
+func formatSorted[T any](values []T) string {
+	sort.Slice(values, func(i, j int) bool {
+		return Compare(values[i], values[j]) < 0
+	})
+	return formatItems(values)
+}
+
+// formatItems returns the items joined in their order
+func formatItems[T any](values []T) string {
+	items := make([]string, len(values))
+	for i, item := range values {
+		items[i] = fmt.Sprintf("%v", item)
+	}
+	return strings.Join(items, ", ")
+}
+
+// rank returns the sort group of the value kind
+func rank(v reflect.Value) int {
+	if !v.IsValid() {
+		return 0
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr, reflect.Float32, reflect.Float64:
+		return 1
+	case reflect.Bool:
+		return 2
+	case reflect.String:
+		return 3
+	}
+	return 4
+}
+
+// compareNumbers compares the numbers, exactly for the integers
+func compareNumbers(a, b reflect.Value) int {
+	ka, kb := numberKind(a), numberKind(b)
+	switch {
+	case ka == reflect.Int && kb == reflect.Int:
+		return order(a.Int(), b.Int())
+	case ka == reflect.Uint && kb == reflect.Uint:
+		return order(a.Uint(), b.Uint())
+	case ka == reflect.Int && kb == reflect.Uint:
+		if a.Int() < 0 {
+			return -1
+		}
+		return order(uint64(a.Int()), b.Uint())
+	case ka == reflect.Uint && kb == reflect.Int:
+		return -compareNumbers(b, a)
+	}
+	return order(toFloat(a), toFloat(b))
+}
+
+// numberKind returns Int, Uint or Float64 for the number kinds
+func numberKind(v reflect.Value) reflect.Kind {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.Int
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return reflect.Uint
+	}
+	return reflect.Float64
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch numberKind(v) {
+	case reflect.Int:
+		return float64(v.Int())
+	case reflect.Uint:
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func order[T int | int64 | uint64 | float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package hset
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+func TestIter(t *testing.T) {
+	set := NewOf(1, 2, 3, 4)
+
+	sum := 0
+	for item := range set.All() {
+		sum += item
+	}
+	tt.Equal(t, 10, sum)
+
+	n := 0
+	for range set.All() {
+		n++
+		break
+	}
+	tt.Equal(t, 1, n)
+
+	set.Each(func(item int) {
+		sum -= item
+	})
+	tt.Equal(t, 0, sum)
+
+	even := func(item int) bool { return item%2 == 0 }
+	tt.Equal(t, []int{2, 4}, sorted(set.Filter(even)))
+	in, out := set.Partition(even)
+	tt.Equal(t, []int{2, 4}, sorted(in))
+	tt.Equal(t, []int{1, 3}, sorted(out))
+
+	strs := Map(set, func(item int) string { return strconv.Itoa(item % 2) })
+	tt.True(t, strs.Same(NewOf("0", "1")))
+
+	tt.True(t, set.Any(even))
+	tt.False(t, set.Every(even))
+	tt.True(t, set.Every(func(item int) bool { return item > 0 }))
+	tt.True(t, NewOf[int]().Every(even))
+
+	tt.Equal(t, []int{4, 3, 2, 1}, set.Sorted(func(a, b int) bool { return a > b }))
+	tt.Equal(t, "1, 2, 3, 4", set.String())
+	tt.Equal(t, "1, 2, 10", NewOf(10, 2, 1).String())
+}
+
+func TestHsetIter(t *testing.T) {
+	set := New()
+	set.Add("b", 2, "a", 1.5, true, nil)
+	tt.Equal(t, "<nil>, 1.5, 2, true, a, b", set.String())
+	tt.Equal(t, "Has Hset:\n<nil>, 1.5, 2, true, a, b", set.String(true))
+
+	str := func(item interface{}) bool {
+		_, ok := item.(string)
+		return ok
+	}
+	tt.Equal(t, "a, b", set.Filter(str).String())
+	in, out := set.Partition(str)
+	tt.Equal(t, 2, in.Len())
+	tt.Equal(t, 4, out.Len())
+}
+
+func TestCompare(t *testing.T) {
+	tt.Equal(t, -1, Compare(1, 2))
+	tt.Equal(t, 0, Compare(int8(2), uint(2)))
+	tt.Equal(t, -1, Compare(-1, uint(0)))
+	tt.Equal(t, 1, Compare(uint64(1<<63), int64(1)))
+	tt.Equal(t, -1, Compare(1, 1.5))
+	tt.Equal(t, -1, Compare(nil, 0))
+	tt.Equal(t, -1, Compare(false, true))
+	tt.Equal(t, -1, Compare(2, "1"))
+	tt.Equal(t, -1, Compare("a", "b"))
+	tt.Equal(t, -1, Compare("z", []int{1}))
+	tt.Equal(t, 1, Compare(struct{ A int }{2}, struct{ A int }{1}))
+}
@@ -0,0 +1,143 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package hset
+
+import (
+	"container/list"
+	"iter"
+	"sync"
+)
+
+// LinkedSet holds elements in go's native map and keeps
+// their insertion order in a linked list
+type LinkedSet struct {
+	items map[interface{}]*list.Element
+	order *list.List
+	sync.RWMutex
+}
+
+var _ Set = (*LinkedSet)(nil)
+
+// NewLinkedSet instantiates a new empty linked set
+func NewLinkedSet() *LinkedSet {
+	return &LinkedSet{items: make(map[interface{}]*list.Element), order: list.New()}
+}
+
+// Add adds the items (one or more) to the set, at the end of the order.
+// Items already in the set keep their position.
+func (set *LinkedSet) Add(items ...interface{}) {
+	set.Lock()
+	for _, item := range items {
+		if _, ok := set.items[item]; !ok {
+			set.items[item] = set.order.PushBack(item)
+		}
+	}
+	set.Unlock()
+}
+
+// Remove removes the items (one or more) from the set.
+func (set *LinkedSet) Remove(items ...interface{}) {
+	set.Lock()
+	for _, item := range items {
+		if e, ok := set.items[item]; ok {
+			set.order.Remove(e)
+			delete(set.items, item)
+		}
+	}
+	set.Unlock()
+}
+
+// Clear clears all values in the set.
+func (set *LinkedSet) Clear() {
+	set.Lock()
+	set.items = make(map[interface{}]*list.Element)
+	set.order.Init()
+	set.Unlock()
+}
+
+// Contains check if items (one or more) are present in the set.
+// All items have to be present in the set for the method to return true.
+// Returns true if no arguments are passed at all.
+func (set *LinkedSet) Contains(items ...interface{}) bool {
+	set.RLock()
+	defer set.RUnlock()
+
+	for _, item := range items {
+		if _, ok := set.items[item]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Empty returns true if set does not contain any elements.
+func (set *LinkedSet) Empty() bool {
+	return set.Len() == 0
+}
+
+// Len returns number of elements within the set.
+func (set *LinkedSet) Len() int {
+	set.RLock()
+	size := len(set.items)
+	set.RUnlock()
+	return size
+}
+
+// Values returns all items in the set, in insertion order.
+func (set *LinkedSet) Values() []interface{} {
+	values := make([]interface{}, 0, set.Len())
+	for item := range set.All() {
+		values = append(values, item)
+	}
+	return values
+}
+
+// All returns an iterator over the items of the set, in insertion order.
+// The set is read locked during the iteration, so the loop body
+// must not modify it.
+func (set *LinkedSet) All() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		set.RLock()
+		defer set.RUnlock()
+
+		for e := set.order.Front(); e != nil; e = e.Next() {
+			if !yield(e.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Same to determine whether the two sets hold the same items.
+func (set *LinkedSet) Same(other Set) bool {
+	if other == nil {
+		return false
+	}
+
+	values := other.Values()
+	set.RLock()
+	defer set.RUnlock()
+
+	if len(set.items) != len(values) {
+		return false
+	}
+	for _, item := range values {
+		if _, ok := set.items[item]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of container, in insertion order
+func (set *LinkedSet) String() string {
+	return formatItems(set.Values())
+}
@@ -0,0 +1,39 @@
+package hset
+
+import (
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+func TestLinkedSet(t *testing.T) {
+	var set Set = NewLinkedSet()
+	set.Add("c", 1, "a")
+	set.Add(1, "b")
+	tt.Equal(t, 4, set.Len())
+	tt.True(t, set.Contains("a", "b", "c", 1))
+	tt.False(t, set.Contains(2))
+	tt.Equal(t, []interface{}{"c", 1, "a", "b"}, set.Values())
+	tt.Equal(t, "c, 1, a, b", set.String())
+
+	set.Remove(1, 2)
+	set.Add(1)
+	tt.Equal(t, []interface{}{"c", "a", "b", 1}, set.Values())
+
+	tree := NewTreeSet(nil)
+	tree.Add(1, "a", "b", "c")
+	tt.True(t, set.Same(tree))
+	tt.False(t, set.Same(nil))
+
+	n := 0
+	for range set.(*LinkedSet).All() {
+		n++
+		break
+	}
+	tt.Equal(t, 1, n)
+
+	set.Clear()
+	tt.True(t, set.(*LinkedSet).Empty())
+	set.Add(2)
+	tt.Equal(t, "2", set.String())
+}
@@ -11,14 +11,19 @@
 /*
 Package hset implements a hset backed by a hash table.
 
+Of is the typed hset of comparable elements, Hset holds interface{} elements.
+Hset is a Set through AsSet.
+TreeSet and LinkedSet implement Set keeping the items sorted or in
+insertion order, ShardedHset spreads the items over locked shards and
+ExpiringSet removes the items after their time-to-live.
+BloomFilter and CuckooFilter are probabilistic sets of a fixed size,
+for the dedup of more items than fit in memory.
+Bag is a multiset counting how many times each item was added.
+
 Structure is thread safe.
 */
 package hset
 
-import (
-	"encoding/json"
-)
-
 // Set hset interface
 type Set interface {
 	Add(items ...interface{})
@@ -30,20 +35,3 @@ type Set interface {
 	Values() []interface{}
 	String() string
 }
-
-// ToJSON outputs the JSON representation of list's elements.
-func (set *Hset) ToJSON() ([]byte, error) {
-	return json.Marshal(set.Values())
-}
-
-// FromJSON populates list's elements from the input JSON representation.
-func (set *Hset) FromJSON(data []byte) error {
-	elements := []interface{}{}
-	err := json.Unmarshal(data, &elements)
-	if err == nil {
-		set.Clear()
-		set.Add(elements...)
-	}
-
-	return err
-}
@@ -0,0 +1,331 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package hset
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"io"
+	"math"
+	"reflect"
+	"sync"
+)
+
+// DefaultShards the number of shards of NewSharded
+const DefaultShards = 32
+
+// ShardedHset holds elements in go's native maps sharded by the item
+// hash, each shard has its own lock, so the goroutines adding and
+// checking different items rarely wait on each other.
+//
+// Len, Values, Same, String and Clear lock all the shards and see a
+// consistent set; Add, Remove and Contains of several items lock their
+// shards one at a time.
+type ShardedHset struct {
+	shards []shard
+	mask   uint64
+	seed   maphash.Seed
+}
+
+var _ Set = (*ShardedHset)(nil)
+
+type shard struct {
+	items map[interface{}]struct{}
+	sync.RWMutex
+	// pad the shards to separate cache lines
+	_ [32]byte
+}
+
+// NewSharded instantiates a new empty sharded hset, the number of shards
+// is rounded up to a power of two, DefaultShards if n <= 0
+func NewSharded(n ...int) *ShardedHset {
+	size := DefaultShards
+	if len(n) > 0 && n[0] > 0 {
+		size = n[0]
+	}
+
+	shards := 1
+	for shards < size {
+		shards <<= 1
+	}
+
+	hset := &ShardedHset{
+		shards: make([]shard, shards),
+		mask:   uint64(shards - 1),
+		seed:   maphash.MakeSeed(),
+	}
+	for i := range hset.shards {
+		hset.shards[i].items = make(map[interface{}]struct{})
+	}
+	return hset
+}
+
+// shardOf returns the shard of the item
+func (hset *ShardedHset) shardOf(item interface{}) *shard {
+	return &hset.shards[hset.hash(item)&hset.mask]
+}
+
+// hash returns the hash of the item, equal for equal items
+func (hset *ShardedHset) hash(item interface{}) uint64 {
+	var n uint64
+	switch v := item.(type) {
+	case string:
+		return maphash.String(hset.seed, v)
+	case int:
+		n = uint64(v)
+	case int8:
+		n = uint64(v)
+	case int16:
+		n = uint64(v)
+	case int32:
+		n = uint64(v)
+	case int64:
+		n = uint64(v)
+	case uint:
+		n = uint64(v)
+	case uint8:
+		n = uint64(v)
+	case uint16:
+		n = uint64(v)
+	case uint32:
+		n = uint64(v)
+	case uint64:
+		n = v
+	case uintptr:
+		n = uint64(v)
+	case float64:
+		if v != 0 {
+			n = math.Float64bits(v)
+		}
+	case float32:
+		if v != 0 {
+			n = uint64(math.Float32bits(v))
+		}
+	case bool:
+		if v {
+			n = 1
+		}
+	default:
+		var h maphash.Hash
+		h.SetSeed(hset.seed)
+		hashValue(&h, reflect.ValueOf(item))
+		return h.Sum64()
+	}
+
+	// spread the sequential numbers over the shards
+	return mix(n)
+}
+
+// hashWriter the hash input, a maphash.Hash or a bytes.Buffer
+type hashWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// hashValue writes the value v to h, so the values equal by == write
+// the same bytes: the pointers and channels by address, the structs,
+// arrays and interfaces by their items
+func hashValue(h hashWriter, v reflect.Value) {
+	var buf [8]byte
+	number := func(n uint64) {
+		binary.LittleEndian.PutUint64(buf[:], n)
+		h.Write(buf[:])
+	}
+	float := func(f float64) {
+		// -0 and +0 are equal
+		if f == 0 {
+			f = 0
+		}
+		number(math.Float64bits(f))
+	}
+
+	if !v.IsValid() {
+		h.WriteByte(0)
+		return
+	}
+	h.WriteByte(byte(v.Kind()))
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			h.WriteByte(1)
+		} else {
+			h.WriteByte(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		number(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		float(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		float(real(v.Complex()))
+		float(imag(v.Complex()))
+	case reflect.String:
+		h.WriteString(v.String())
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		number(uint64(v.Pointer()))
+	case reflect.Interface:
+		if !v.IsNil() {
+			hashValue(h, v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			hashValue(h, v.Field(i))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i))
+		}
+	}
+}
+
+// mix the splitmix64 finalizer, spreading the hash bits
+func mix(n uint64) uint64 {
+	n += 0x9e3779b97f4a7c15
+	n = (n ^ (n >> 30)) * 0xbf58476d1ce4e5b9
+	n = (n ^ (n >> 27)) * 0x94d049bb133111eb
+	return n ^ (n >> 31)
+}
+
+// lockAll read locks all the shards, or write locks them if write is true;
+// it returns the unlock func
+func (hset *ShardedHset) lockAll(write bool) func() {
+	for i := range hset.shards {
+		if write {
+			hset.shards[i].Lock()
+		} else {
+			hset.shards[i].RLock()
+		}
+	}
+
+	return func() {
+		for i := range hset.shards {
+			if write {
+				hset.shards[i].Unlock()
+			} else {
+				hset.shards[i].RUnlock()
+			}
+		}
+	}
+}
+
+// Add adds the items (one or more) to the hset.
+func (hset *ShardedHset) Add(items ...interface{}) {
+	for _, item := range items {
+		s := hset.shardOf(item)
+		s.Lock()
+		s.items[item] = itemExists
+		s.Unlock()
+	}
+}
+
+// Remove removes the items (one or more) from the hset.
+func (hset *ShardedHset) Remove(items ...interface{}) {
+	for _, item := range items {
+		s := hset.shardOf(item)
+		s.Lock()
+		delete(s.items, item)
+		s.Unlock()
+	}
+}
+
+// Contains check if items (one or more) are present in the hset.
+// All items have to be present in the hset for the method to return true.
+// Returns true if no arguments are passed at all.
+func (hset *ShardedHset) Contains(items ...interface{}) bool {
+	for _, item := range items {
+		if !hset.Exists(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// Exists returns a bool indicating if the given item exists in the set.
+func (hset *ShardedHset) Exists(item interface{}) bool {
+	s := hset.shardOf(item)
+	s.RLock()
+	_, ok := s.items[item]
+	s.RUnlock()
+	return ok
+}
+
+// Clear clears all values in the hset.
+func (hset *ShardedHset) Clear() {
+	unlock := hset.lockAll(true)
+	for i := range hset.shards {
+		hset.shards[i].items = make(map[interface{}]struct{})
+	}
+	unlock()
+}
+
+// Empty returns true if hset does not contain any elements.
+func (hset *ShardedHset) Empty() bool {
+	return hset.Len() == 0
+}
+
+// Len returns number of elements within the hset.
+func (hset *ShardedHset) Len() int {
+	unlock := hset.lockAll(false)
+	defer unlock()
+	return hset.len()
+}
+
+func (hset *ShardedHset) len() int {
+	size := 0
+	for i := range hset.shards {
+		size += len(hset.shards[i].items)
+	}
+	return size
+}
+
+// Values returns all items in the hset.
+func (hset *ShardedHset) Values() []interface{} {
+	unlock := hset.lockAll(false)
+	defer unlock()
+
+	values := make([]interface{}, 0, hset.len())
+	for i := range hset.shards {
+		for item := range hset.shards[i].items {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// Same to determine whether the two sets hold the same items.
+func (hset *ShardedHset) Same(other Set) bool {
+	if other == nil {
+		return false
+	}
+
+	values := other.Values()
+	unlock := hset.lockAll(false)
+	defer unlock()
+
+	if hset.len() != len(values) {
+		return false
+	}
+	for _, item := range values {
+		if _, ok := hset.shardOf(item).items[item]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of container,
+// the items are sorted by Compare
+func (hset *ShardedHset) String() string {
+	return formatSorted(hset.Values())
+}
@@ -0,0 +1,88 @@
+package hset
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+func TestSharded(t *testing.T) {
+	var set Set = NewSharded(5)
+	tt.Equal(t, 8, len(set.(*ShardedHset).shards))
+	tt.Equal(t, DefaultShards, len(NewSharded().shards))
+
+	set.Add(3, "b", 1.5, int64(3), true, struct{ A int }{1})
+	set.Add(3)
+	tt.Equal(t, 6, set.Len())
+	tt.True(t, set.Contains(3, int64(3), "b", 1.5, true, struct{ A int }{1}))
+	tt.False(t, set.Contains(4))
+	tt.Equal(t, "1.5, 3, 3, true, b, {1}", set.String())
+
+	set.Remove(int64(3), true)
+	tt.Equal(t, 4, len(set.Values()))
+
+	linked := NewLinkedSet()
+	linked.Add(3, "b", 1.5, struct{ A int }{1})
+	tt.True(t, set.Same(linked))
+	tt.False(t, set.Same(NewLinkedSet()))
+	tt.False(t, set.Same(nil))
+
+	set.Clear()
+	tt.True(t, set.(*ShardedHset).Empty())
+
+	sharded := NewSharded()
+	sharded.Add(0.0)
+	tt.True(t, sharded.Exists(math.Copysign(0, -1)))
+}
+
+func TestShardedConcurrent(t *testing.T) {
+	set := NewSharded(4)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				set.Add("url/" + strconv.Itoa(i))
+				set.Exists("url/" + strconv.Itoa(i+g))
+				set.Len()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	tt.Equal(t, 1000, set.Len())
+	tt.Equal(t, 1000, len(set.Values()))
+}
+
+func TestShardedHash(t *testing.T) {
+	type item struct {
+		A int
+		F float64
+		I interface{}
+	}
+
+	set := NewSharded()
+	p := &item{A: 1}
+	set.Add(p)
+	// a pointer is hashed by address, not by the value it points to
+	p.A = 2
+	tt.True(t, set.Exists(p))
+	tt.False(t, set.Exists(&item{A: 2}))
+	set.Remove(p)
+	tt.Equal(t, 0, set.Len())
+
+	set.Add(item{F: 0.0, I: [2]float64{0.0, 1}}, [1]interface{}{math.Copysign(0, -1)})
+	tt.True(t, set.Exists(item{F: math.Copysign(0, -1), I: [2]float64{math.Copysign(0, -1), 1}}))
+	tt.True(t, set.Exists([1]interface{}{0.0}))
+	tt.False(t, set.Exists(item{F: 1}))
+
+	ch := make(chan int)
+	set.Add(ch, nil)
+	tt.True(t, set.Contains(ch, nil))
+	tt.False(t, set.Exists(make(chan int)))
+}
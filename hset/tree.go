@@ -0,0 +1,387 @@
+// Copyright 2017 The go-vgo Project Developers. See the COPYRIGHT
+// file at the top-level directory of this distribution and at
+// https://github.com/go-vgo/gt/blob/master/LICENSE
+//
+// Licensed under the Apache License, Version 2.0 <LICENSE-APACHE or
+// http://www.apache.org/licenses/LICENSE-2.0>
+//
+// This file may not be copied, modified, or distributed
+// except according to those terms.
+
+package hset
+
+import (
+	"iter"
+	"sync"
+)
+
+// Comparator returns a negative number, 0 or a positive number
+// as a sorts before, with or after b
+type Comparator func(a, b interface{}) int
+
+// TreeSet holds elements sorted by a comparator in an AVL tree
+type TreeSet struct {
+	root    *treeNode
+	size    int
+	compare Comparator
+	sync.RWMutex
+}
+
+var _ Set = (*TreeSet)(nil)
+
+type treeNode struct {
+	item        interface{}
+	left, right *treeNode
+	height      int
+}
+
+// NewTreeSet instantiates a new empty tree set sorted by
+// the comparator, Compare if it is nil
+func NewTreeSet(compare Comparator) *TreeSet {
+	if compare == nil {
+		compare = Compare
+	}
+	return &TreeSet{compare: compare}
+}
+
+// Add adds the items (one or more) to the set.
+func (set *TreeSet) Add(items ...interface{}) {
+	set.Lock()
+	for _, item := range items {
+		var added bool
+		set.root, added = set.insert(set.root, item)
+		if added {
+			set.size++
+		}
+	}
+	set.Unlock()
+}
+
+// Remove removes the items (one or more) from the set.
+func (set *TreeSet) Remove(items ...interface{}) {
+	set.Lock()
+	for _, item := range items {
+		var removed bool
+		set.root, removed = set.delete(set.root, item)
+		if removed {
+			set.size--
+		}
+	}
+	set.Unlock()
+}
+
+// Clear clears all values in the set.
+func (set *TreeSet) Clear() {
+	set.Lock()
+	set.root, set.size = nil, 0
+	set.Unlock()
+}
+
+// Contains check if items (one or more) are present in the set.
+// All items have to be present in the set for the method to return true.
+// Returns true if no arguments are passed at all.
+func (set *TreeSet) Contains(items ...interface{}) bool {
+	set.RLock()
+	defer set.RUnlock()
+
+	for _, item := range items {
+		if set.find(item) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Empty returns true if set does not contain any elements.
+func (set *TreeSet) Empty() bool {
+	return set.Len() == 0
+}
+
+// Len returns number of elements within the set.
+func (set *TreeSet) Len() int {
+	set.RLock()
+	size := set.size
+	set.RUnlock()
+	return size
+}
+
+// Values returns all items in the set, in order.
+func (set *TreeSet) Values() []interface{} {
+	values := make([]interface{}, 0, set.Len())
+	for item := range set.All() {
+		values = append(values, item)
+	}
+	return values
+}
+
+// All returns an iterator over the items of the set, in order.
+// The set is read locked during the iteration, so the loop body
+// must not modify it.
+func (set *TreeSet) All() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		set.RLock()
+		defer set.RUnlock()
+		walk(set.root, nil, nil, set.compare, yield)
+	}
+}
+
+// Same to determine whether the two sets hold the same items.
+func (set *TreeSet) Same(other Set) bool {
+	if other == nil {
+		return false
+	}
+
+	values := other.Values()
+	set.RLock()
+	defer set.RUnlock()
+
+	if set.size != len(values) {
+		return false
+	}
+	for _, item := range values {
+		if set.find(item) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a string representation of container, in order
+func (set *TreeSet) String() string {
+	return formatItems(set.Values())
+}
+
+// Min returns the least item, false if the set is empty.
+func (set *TreeSet) Min() (interface{}, bool) {
+	set.RLock()
+	defer set.RUnlock()
+
+	n := set.root
+	if n == nil {
+		return nil, false
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n.item, true
+}
+
+// Max returns the greatest item, false if the set is empty.
+func (set *TreeSet) Max() (interface{}, bool) {
+	set.RLock()
+	defer set.RUnlock()
+
+	n := set.root
+	if n == nil {
+		return nil, false
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n.item, true
+}
+
+// Floor returns the greatest item less than or equal to the item,
+// false if there is none.
+func (set *TreeSet) Floor(item interface{}) (interface{}, bool) {
+	set.RLock()
+	defer set.RUnlock()
+
+	var floor *treeNode
+	for n := set.root; n != nil; {
+		c := set.compare(item, n.item)
+		switch {
+		case c == 0:
+			return n.item, true
+		case c < 0:
+			n = n.left
+		default:
+			floor, n = n, n.right
+		}
+	}
+	if floor == nil {
+		return nil, false
+	}
+	return floor.item, true
+}
+
+// Ceiling returns the least item greater than or equal to the item,
+// false if there is none.
+func (set *TreeSet) Ceiling(item interface{}) (interface{}, bool) {
+	set.RLock()
+	defer set.RUnlock()
+
+	var ceiling *treeNode
+	for n := set.root; n != nil; {
+		c := set.compare(item, n.item)
+		switch {
+		case c == 0:
+			return n.item, true
+		case c > 0:
+			n = n.right
+		default:
+			ceiling, n = n, n.left
+		}
+	}
+	if ceiling == nil {
+		return nil, false
+	}
+	return ceiling.item, true
+}
+
+// Range returns the items from lo to hi, both inclusive, in order.
+// A nil bound is open, e.g. Range(nil, hi) returns every item up to
+// hi, so a nil item can't be used as a bound.
+func (set *TreeSet) Range(lo, hi interface{}) []interface{} {
+	set.RLock()
+	defer set.RUnlock()
+
+	values := []interface{}{}
+	walk(set.root, lo, hi, set.compare, func(item interface{}) bool {
+		values = append(values, item)
+		return true
+	})
+	return values
+}
+
+// walk yields the items of the tree between lo and hi in order,
+// nil bounds are open; it returns false if yield stopped it
+func walk(n *treeNode, lo, hi interface{}, compare Comparator, yield func(interface{}) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	aboveLo := lo == nil || compare(n.item, lo) >= 0
+	belowHi := hi == nil || compare(n.item, hi) <= 0
+	if aboveLo && !walk(n.left, lo, hi, compare, yield) {
+		return false
+	}
+	if aboveLo && belowHi && !yield(n.item) {
+		return false
+	}
+	if belowHi {
+		return walk(n.right, lo, hi, compare, yield)
+	}
+	return true
+}
+
+// find returns the node of the item, nil if it is not in the set
+func (set *TreeSet) find(item interface{}) *treeNode {
+	n := set.root
+	for n != nil {
+		c := set.compare(item, n.item)
+		switch {
+		case c == 0:
+			return n
+		case c < 0:
+			n = n.left
+		default:
+			n = n.right
+		}
+	}
+	return nil
+}
+
+// insert adds the item under n, returning the new subtree root
+// and whether the item was added
+func (set *TreeSet) insert(n *treeNode, item interface{}) (*treeNode, bool) {
+	if n == nil {
+		return &treeNode{item: item, height: 1}, true
+	}
+
+	var added bool
+	c := set.compare(item, n.item)
+	switch {
+	case c == 0:
+		return n, false
+	case c < 0:
+		n.left, added = set.insert(n.left, item)
+	default:
+		n.right, added = set.insert(n.right, item)
+	}
+	return rebalance(n), added
+}
+
+// delete removes the item under n, returning the new subtree root
+// and whether the item was removed
+func (set *TreeSet) delete(n *treeNode, item interface{}) (*treeNode, bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	var removed bool
+	c := set.compare(item, n.item)
+	switch {
+	case c < 0:
+		n.left, removed = set.delete(n.left, item)
+	case c > 0:
+		n.right, removed = set.delete(n.right, item)
+	default:
+		if n.left == nil {
+			return n.right, true
+		}
+		if n.right == nil {
+			return n.left, true
+		}
+
+		var min *treeNode
+		n.right, min = deleteMin(n.right)
+		min.left, min.right = n.left, n.right
+		n, removed = min, true
+	}
+	return rebalance(n), removed
+}
+
+// deleteMin removes the least node under n, returning the new
+// subtree root and the removed node
+func deleteMin(n *treeNode) (*treeNode, *treeNode) {
+	if n.left == nil {
+		return n.right, n
+	}
+
+	var min *treeNode
+	n.left, min = deleteMin(n.left)
+	return rebalance(n), min
+}
+
+func height(n *treeNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// rebalance restores the AVL balance of n after an insert or delete
+// under it, returning the new subtree root
+func rebalance(n *treeNode) *treeNode {
+	n.height = 1 + max(height(n.left), height(n.right))
+	switch balance := height(n.left) - height(n.right); {
+	case balance > 1:
+		if height(n.left.left) < height(n.left.right) {
+			n.left = rotateLeft(n.left)
+		}
+		return rotateRight(n)
+	case balance < -1:
+		if height(n.right.right) < height(n.right.left) {
+			n.right = rotateRight(n.right)
+		}
+		return rotateLeft(n)
+	}
+	return n
+}
+
+func rotateLeft(n *treeNode) *treeNode {
+	r := n.right
+	n.right, r.left = r.left, n
+	n.height = 1 + max(height(n.left), height(n.right))
+	r.height = 1 + max(height(r.left), height(r.right))
+	return r
+}
+
+func rotateRight(n *treeNode) *treeNode {
+	l := n.left
+	n.left, l.right = l.right, n
+	n.height = 1 + max(height(n.left), height(n.right))
+	l.height = 1 + max(height(l.left), height(l.right))
+	return l
+}
@@ -0,0 +1,119 @@
+package hset
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/vcaesar/tt"
+)
+
+// checkTree checks the order and the AVL balance of the tree,
+// returning its height
+func checkTree(t *testing.T, n *treeNode, compare Comparator) int {
+	if n == nil {
+		return 0
+	}
+
+	if n.left != nil {
+		tt.True(t, compare(n.left.item, n.item) < 0)
+	}
+	if n.right != nil {
+		tt.True(t, compare(n.right.item, n.item) > 0)
+	}
+
+	l, r := checkTree(t, n.left, compare), checkTree(t, n.right, compare)
+	tt.True(t, l-r <= 1 && r-l <= 1)
+	tt.Equal(t, 1+max(l, r), n.height)
+	return n.height
+}
+
+func TestTreeSet(t *testing.T) {
+	var set Set = NewTreeSet(nil)
+	set.Add(5, 1, 3)
+	set.Add(3, 9)
+	tt.Equal(t, 4, set.Len())
+	tt.True(t, set.Contains(1, 3, 5, 9))
+	tt.False(t, set.Contains(2))
+	tt.Equal(t, []interface{}{1, 3, 5, 9}, set.Values())
+	tt.Equal(t, "1, 3, 5, 9", set.String())
+
+	linked := NewLinkedSet()
+	tt.False(t, set.Same(linked))
+	linked.Add(9, 5, 3, 1)
+	tt.True(t, set.Same(linked))
+	tt.False(t, set.Same(nil))
+
+	tree := set.(*TreeSet)
+	min, ok := tree.Min()
+	tt.True(t, ok)
+	tt.Equal(t, 1, min)
+	max, _ := tree.Max()
+	tt.Equal(t, 9, max)
+
+	floor, ok := tree.Floor(4)
+	tt.True(t, ok)
+	tt.Equal(t, 3, floor)
+	floor, _ = tree.Floor(5)
+	tt.Equal(t, 5, floor)
+	_, ok = tree.Floor(0)
+	tt.False(t, ok)
+
+	ceiling, ok := tree.Ceiling(4)
+	tt.True(t, ok)
+	tt.Equal(t, 5, ceiling)
+	_, ok = tree.Ceiling(10)
+	tt.False(t, ok)
+
+	tt.Equal(t, []interface{}{3, 5}, tree.Range(2, 5))
+	tt.Equal(t, []interface{}{}, tree.Range(6, 8))
+	tt.Equal(t, []interface{}{1, 3}, tree.Range(nil, 4))
+	tt.Equal(t, []interface{}{5, 9}, tree.Range(5, nil))
+
+	set.Remove(3, 4)
+	tt.Equal(t, []interface{}{1, 5, 9}, set.Values())
+	set.Clear()
+	tt.True(t, tree.Empty())
+	_, ok = tree.Min()
+	tt.False(t, ok)
+	_, ok = tree.Max()
+	tt.False(t, ok)
+
+	desc := NewTreeSet(func(a, b interface{}) int {
+		return Compare(b, a)
+	})
+	desc.Add("a", "c", "b")
+	tt.Equal(t, "c, b, a", desc.String())
+	floor, _ = desc.Floor("bb")
+	tt.Equal(t, "c", floor)
+}
+
+func TestTreeSetRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	set := NewTreeSet(nil)
+	m := map[int]bool{}
+
+	for i := 0; i < 2000; i++ {
+		n := r.Intn(500)
+		if r.Intn(3) == 0 {
+			set.Remove(n)
+			delete(m, n)
+		} else {
+			set.Add(n)
+			m[n] = true
+		}
+	}
+	checkTree(t, set.root, set.compare)
+
+	keys := []interface{}{}
+	ints := []int{}
+	for k := range m {
+		ints = append(ints, k)
+	}
+	sort.Ints(ints)
+	for _, k := range ints {
+		keys = append(keys, k)
+	}
+	tt.Equal(t, len(m), set.Len())
+	tt.Equal(t, keys, set.Values())
+}
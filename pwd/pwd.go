@@ -11,12 +11,16 @@
 package pwd
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	// "crypto/bcrypt"
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/md5"
+	"crypto/rand"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
@@ -101,3 +105,50 @@ func Check(hashed, pwd string) bool {
 	salt := hashed[len(hashed)-4:]
 	return Sha256(Md5(pwd)+salt)+salt == hashed
 }
+
+// AesEncrypt encrypt and authenticate the plaintext by AES-GCM,
+// the key is 16, 24 or 32 bytes, the random nonce is prepended.
+// The optional additional data is authenticated but not encrypted,
+// AesDecrypt needs the same data.
+func AesEncrypt(key, plaintext []byte, data ...[]byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additional(data)), nil
+}
+
+// AesDecrypt decrypt and authenticate the AesEncrypt ciphertext
+func AesDecrypt(key, ciphertext []byte, data ...[]byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("pwd: ciphertext too short")
+	}
+	nonce := ciphertext[:gcm.NonceSize()]
+	return gcm.Open(nil, nonce, ciphertext[gcm.NonceSize():], additional(data))
+}
+
+// additional returns the optional additional data of AES-GCM
+func additional(data [][]byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	return data[0]
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
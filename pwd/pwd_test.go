@@ -1,6 +1,7 @@
 package pwd
 
 import (
+	"crypto/rand"
 	"testing"
 
 	"github.com/vcaesar/tt"
@@ -16,3 +17,47 @@ func TestPwGen(t *testing.T) {
 
 	tt.True(t, b)
 }
+
+// aesKey returns a random AES-256 key
+func aesKey(t *testing.T) []byte {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	tt.Nil(t, err)
+	return key
+}
+
+func TestAes(t *testing.T) {
+	key := aesKey(t)
+	tt.Equal(t, 32, len(key))
+
+	c, err := AesEncrypt(key, []byte(testPwd))
+	tt.Nil(t, err)
+
+	p, err := AesDecrypt(key, c)
+	tt.Nil(t, err)
+	tt.Equal(t, testPwd, string(p))
+
+	c[len(c)-1] ^= 1
+	_, err = AesDecrypt(key, c)
+	tt.NotNil(t, err)
+
+	_, err = AesDecrypt(aesKey(t), c)
+	tt.NotNil(t, err)
+	_, err = AesEncrypt([]byte("short"), c)
+	tt.NotNil(t, err)
+}
+
+func TestAesData(t *testing.T) {
+	key := aesKey(t)
+	c, err := AesEncrypt(key, []byte(testPwd), []byte("db.pass"))
+	tt.Nil(t, err)
+
+	p, err := AesDecrypt(key, c, []byte("db.pass"))
+	tt.Nil(t, err)
+	tt.Equal(t, testPwd, string(p))
+
+	_, err = AesDecrypt(key, c, []byte("db.user"))
+	tt.NotNil(t, err)
+	_, err = AesDecrypt(key, c)
+	tt.NotNil(t, err)
+}